@@ -2,23 +2,84 @@ package main
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"strings"
 )
 
 type Option struct {
-	Delimiter string
-	UseRegexp bool
-	Count     int
-	Margin    string
-	Justify   string
+	Delimiter    string
+	UseRegexp    bool
+	Count        int
+	Margin       string
+	Justify      string
+	DelimJustify string
+	MaxWidth     string
+	Overflow     string
+	MinWidth     int
+	Widths       string
+	WidthModel   string
+	EastAsian    string
+	Fill         string
+	FillCells    string
+	MarginFill   string
+	Markdown     bool
+	Output       string
+	Border       string
+	Header       bool
+	TextOnly     bool
+	Input        string
+	Requote      bool
+	Keys         string
+	Cut          string
+	Gap          int
+	Auto         bool
+	Compact      bool
+	Indent       string
+	GrowOnly     bool
+	Column       string
+	Round        int
+	MaxPad       int
+}
+
+type Row struct {
+	text    string
+	cells   []string
+	padding *Padding
 }
 
 type Aligner struct {
 	delimiter *Delimiter
 	margin    *Margin
 	padding   *Padding
+	limit     *Limit
 	space     *Space
-	cells     [][]string
+	measure   *Measure
+	markdown  bool
+	output    Output
+	border    *gridStyle
+	header    bool
+	textOnly  bool
+	input     Input
+	requote   bool
+	keys      []string
+	cutter    *Cutter
+	auto      bool
+	count     int
+	keepGap   bool
+	detected  *DelimiterCandidate
+	compact   bool
+	indent    string
+	hasIndent bool
+	growOnly  bool
+	columns   []int
+	round     int
+	maxPad    int
+	section   *Section
+	sections  []*Padding
+	rows      []*Row
+	cells     int
+	lines     []string
 }
 
 func NewAligner(opt *Option) (a *Aligner, err error) {
@@ -26,57 +87,548 @@ func NewAligner(opt *Option) (a *Aligner, err error) {
 		opt = &Option{}
 	}
 
+	wm, err := ParseWidthModel(opt.WidthModel)
+	if err != nil {
+		return nil, err
+	}
+	ms := NewMeasure(wm)
+	ms.cond, err = ParseEastAsianWidth(opt.EastAsian)
+	if err != nil {
+		return nil, err
+	}
+
 	d, err := NewDelimiter(opt.Delimiter, opt.UseRegexp, opt.Count)
 	if err != nil {
 		return nil, err
 	}
+	if opt.Gap != 0 {
+		if err := d.SetGap(opt.Gap); err != nil {
+			return nil, err
+		}
+	}
+	if opt.Auto && (opt.Delimiter != "" || opt.Gap != 0) {
+		return nil, fmt.Errorf("delimiter: auto cannot be used with DELIM")
+	}
+	if opt.Auto && opt.Cut != "" {
+		return nil, fmt.Errorf("delimiter: auto cannot be used with cut")
+	}
+	if opt.Auto && opt.Input != "" && opt.Input != "text" {
+		return nil, fmt.Errorf("delimiter: auto cannot be used with input: %s", opt.Input)
+	}
 	m, err := NewMargin(opt.Margin)
 	if err != nil {
 		return nil, err
 	}
+	if opt.Margin == "" && opt.Gap > 0 {
+		m.left, m.right = opt.Gap, opt.Gap
+	}
+	m.measure = ms
+	if opt.MarginFill != "" {
+		m.SetFill(opt.MarginFill)
+	}
 	p, err := NewPadding(opt.Justify)
 	if err != nil {
 		return nil, err
 	}
+	p.delimited = d.HasDelimiterCells()
+	if err := p.SetDelimiterJustifies(opt.DelimJustify); err != nil {
+		return nil, err
+	}
+	p.measure = ms
+	if opt.FillCells != "" && opt.Fill == "" {
+		return nil, fmt.Errorf("padding: fill cells cannot be used without fill")
+	}
+	if opt.Fill != "" {
+		fc, err := ParseCellIndexes(opt.FillCells)
+		if err != nil {
+			return nil, err
+		}
+		p.SetFill(opt.Fill, fc)
+	}
+	width, err := ParseWidths(opt.Widths)
+	if err != nil {
+		return nil, err
+	}
+	p.SetWidth(opt.MinWidth, width)
+	l, err := NewLimit(opt.MaxWidth, opt.Overflow)
+	if err != nil {
+		return nil, err
+	}
+	l.measure = ms
+	o, err := ParseOutput(opt.Output)
+	if err != nil {
+		return nil, err
+	}
+	in, err := ParseInput(opt.Input)
+	if err != nil {
+		return nil, err
+	}
+	if in != InputText {
+		p.delimited = opt.Requote
+	}
+	cu, err := NewCutter(opt.Cut)
+	if err != nil {
+		return nil, err
+	}
+	if cu != nil {
+		cu.measure = ms
+		p.delimited = false
+	}
+	b, err := ParseBorder(opt.Border)
+	if err != nil {
+		return nil, err
+	}
+	if b != nil && o != OutputText {
+		return nil, fmt.Errorf("border: cannot be used with output: %s", opt.Output)
+	}
+	if b == unicodeStyle && ms.cond.EastAsianWidth {
+		b = asciiStyle
+	}
+	indent, hasIndent, err := ParseIndent(opt.Indent)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := ParseColumns(opt.Column)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Round < 0 {
+		return nil, fmt.Errorf("padding: invalid round: %d", opt.Round)
+	}
+	if opt.Round > 1 && m.keyed != nil {
+		return nil, fmt.Errorf("padding: round cannot be used with keyed margin: %s", opt.Margin)
+	}
+	if opt.MaxPad < 0 {
+		return nil, fmt.Errorf("padding: invalid max pad: %d", opt.MaxPad)
+	}
+	l.delimited = p.delimited
+	m.delimited = p.delimited
 	s := NewSpace()
 	return &Aligner{
 		delimiter: d,
 		margin:    m,
 		padding:   p,
+		limit:     l,
 		space:     s,
+		measure:   ms,
+		markdown:  opt.Markdown,
+		output:    o,
+		border:    b,
+		header:    opt.Header,
+		textOnly:  opt.TextOnly,
+		input:     in,
+		requote:   opt.Requote,
+		keys:      ParseKeys(opt.Keys),
+		cutter:    cu,
+		auto:      opt.Auto,
+		count:     opt.Count,
+		keepGap:   opt.Margin != "",
+		compact:   opt.Compact,
+		indent:    indent,
+		hasIndent: hasIndent,
+		growOnly:  opt.GrowOnly,
+		columns:   columns,
+		round:     opt.Round,
+		maxPad:    opt.MaxPad,
+		section:   &Section{},
+		sections:  []*Padding{p},
 	}, nil
 }
 
 func (a *Aligner) AddRow(s string) {
-	row := a.delimiter.Split(a.space.Trim(s))
-	a.cells = append(a.cells, row)
+	switch {
+	case a.markdown:
+		a.lines = append(a.lines, s)
+	case a.cutter != nil:
+		a.addRow(s, a.cutter.Cut(s))
+	default:
+		a.addRow(s, a.delimiter.Split(a.space.Trim(s)))
+	}
+}
+
+func (a *Aligner) AddRecord(record []string) error {
+	sep := a.input.separator()
+	row, err := RecordCells(record, sep, a.requote)
+	if err != nil {
+		return err
+	}
+	a.addRow(RowText(row, sep, a.requote), row)
+	return nil
+}
 
+func (a *Aligner) addRow(s string, row []string) {
+	if len(row) > a.cells {
+		a.cells = len(row)
+	}
 	if len(row) > 1 {
 		a.space.UpdateLeadingWidth(s)
-		a.padding.UpdateWidth(row)
+		lines := a.rowLines(row)
+		if a.maxPad > 0 {
+			a.updateSection(lines)
+		}
+		for _, line := range lines {
+			a.padding.UpdateWidth(a.limit.Measured(line))
+		}
+		if a.growOnly && a.input == InputText {
+			if starts, ends := a.spansIn(s, row); starts != nil {
+				p := a.padding
+				p.Grow(a.margin.Widths(a.space.leadingWidth, starts, ends, row, func(i int) bool {
+					return p.resolve(i, p.justKindAt(i, a.cells)) == JustRight
+				}))
+			}
+		}
 	}
+	a.rows = append(a.rows, &Row{text: s, cells: row, padding: a.padding})
 }
 
-func (a *Aligner) ReadAll(r io.Reader) error {
+func (a *Aligner) rowLines(row []string) [][]string {
+	var lines [][]string
+	for _, line := range SplitLines(row) {
+		if len(line) > 1 {
+			lines = append(lines, a.limit.Apply(line)...)
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (a *Aligner) updateSection(lines [][]string) {
+	var width []int
+	for _, line := range lines {
+		measured := a.limit.Measured(line)
+		for i := 0; i < len(measured)-1; i++ {
+			w := a.measure.StringWidth(measured[i])
+			switch {
+			case i == len(width):
+				width = append(width, w)
+			case w > width[i]:
+				width[i] = w
+			}
+		}
+	}
+	if !a.section.Fits(width, a.maxPad) {
+		a.section = &Section{}
+		a.padding = a.padding.Fork()
+		a.sections = append(a.sections, a.padding)
+	}
+	a.section.Add(width)
+}
+
+func (a *Aligner) spansIn(s string, row []string) (starts, ends []int) {
+	starts, ends = make([]int, len(row)), make([]int, len(row))
+	i := 0
+	for k, cell := range row {
+		j := strings.Index(s[i:], cell)
+		if j == -1 {
+			return nil, nil
+		}
+		i += j
+		starts[k] = a.measure.StringWidth(s[:i])
+		ends[k] = starts[k] + a.measure.StringWidth(cell)
+		i += len(cell)
+	}
+	return starts, ends
+}
+
+func (a *Aligner) roundWidths() {
+	for _, p := range a.sections {
+		p.Round(a.round, a.indentWidth(), func(i int) int {
+			return a.margin.gap(i, nil)
+		})
+	}
+}
+
+func (a *Aligner) SectionWidths() [][]int {
+	a.roundWidths()
+	width := make([][]int, len(a.sections))
+	for i, p := range a.sections {
+		width[i] = p.Width()
+	}
+	return width
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
 	s := bufio.NewScanner(r)
 	for s.Scan() {
-		a.AddRow(s.Text())
+		lines = append(lines, s.Text())
 	}
-	return s.Err()
+	return lines, s.Err()
 }
 
-func (a *Aligner) format(cells []string) string {
-	if len(cells) == 1 {
-		return cells[0]
+func (a *Aligner) ReadAll(r io.Reader) error {
+	switch a.input {
+	case InputCSV, InputTSV:
+		return ReadRecords(r, a.input, a.AddRecord)
+	case InputJSON, InputJSONL:
+		return ReadJSON(r, a.input, a.keys, a.AddRecord)
 	}
-	return a.space.Adjust(a.margin.Join(a.padding.Format(cells)))
+	lines, err := readLines(r)
+	if err != nil {
+		return err
+	}
+	if a.cutter != nil && a.cutter.auto {
+		a.cutter.Detect(lines)
+	}
+	if a.auto {
+		if err := a.detectDelimiter(lines); err != nil {
+			return err
+		}
+	}
+	for _, line := range lines {
+		a.AddRow(line)
+	}
+	return nil
+}
+
+func (a *Aligner) detectDelimiter(lines []string) error {
+	c := DetectDelimiter(lines)
+	a.detected = c
+	if c == nil {
+		return nil
+	}
+	d, err := NewDelimiter(c.expr, false, a.count)
+	if err != nil {
+		return err
+	}
+	if c.gap > 0 {
+		if err := d.SetGap(c.gap); err != nil {
+			return err
+		}
+		if !a.keepGap {
+			a.margin.left, a.margin.right = c.gap, c.gap
+		}
+	}
+	a.delimiter = d
+	a.padding.delimited = d.HasDelimiterCells()
+	a.limit.delimited = a.padding.delimited
+	a.margin.delimited = a.padding.delimited
+	return nil
+}
+
+func (a *Aligner) DetectedDelimiter() string {
+	if a.detected == nil {
+		return "spaces"
+	}
+	return a.detected.Name
+}
+
+func (a *Aligner) indentWidth() int {
+	switch {
+	case a.hasIndent:
+		return len(a.indent)
+	case a.space.leadingWidth == IntMax:
+		return 0
+	}
+	return a.space.leadingWidth
+}
+
+func (a *Aligner) padCells(p *Padding, cells []string) []string {
+	padded := make([]string, len(cells))
+	copy(padded, cells)
+	if a.compact {
+		return padded
+	}
+	return p.Format(padded, a.cells)
+}
+
+func (a *Aligner) format(p *Padding, cells []string) string {
+	line := cells[0]
+	switch {
+	case len(cells) == 1 && (line == "" || !a.hasIndent):
+		return line
+	case len(cells) > 1:
+		padded := a.padCells(p, cells)
+		line = a.margin.JoinWith(padded, cells)
+		if a.columns != nil {
+			line = a.margin.JoinAt(a.indentWidth(), padded, cells, a.columns)
+		}
+	}
+	if a.hasIndent {
+		return a.space.AdjustWith(a.indent, line)
+	}
+	return a.space.Adjust(line)
+}
+
+func (a *Aligner) textCells(row []string) []string {
+	if !a.padding.delimited {
+		return row
+	}
+	t := make([]string, 0, len(row)/2+1)
+	for i := 0; i < len(row); i += 2 {
+		t = append(t, row[i])
+	}
+	return t
+}
+
+func (a *Aligner) textIndex(k int) int {
+	if a.padding.delimited {
+		return k * 2
+	}
+	return k
+}
+
+func (a *Aligner) table() *Table {
+	var rows [][]string
+	var pw []int
+	header := 0
+	for _, r := range a.rows {
+		row := r.cells
+		for i, w := range r.padding.Width() {
+			switch {
+			case i == len(pw):
+				pw = append(pw, w)
+			case w > pw[i]:
+				pw[i] = w
+			}
+		}
+		lines := SplitLines(row)
+		if a.border != nil {
+			lines = a.rowLines(row)
+		}
+		if header == 0 && (len(row) > 1 || row[0] != "") {
+			header = len(lines)
+		}
+		for _, line := range lines {
+			cells := a.textCells(line)
+			switch a.output {
+			case OutputMarkdown:
+				cells = escapeMarkdownCells(cells)
+			case OutputOrg:
+				cells = escapeOrgCells(cells)
+			}
+			rows = append(rows, cells)
+		}
+	}
+	t := NewTable(a.measure, rows)
+	if a.border != nil {
+		t.header = 0
+		if a.header {
+			t.header = header
+		}
+		width := make([]int, len(t.width))
+		for k := range width {
+			if i := a.textIndex(k); i < len(pw) {
+				width[k] = pw[i]
+			}
+		}
+		t.SetMinWidth(width)
+	}
+	js := make([]Justify, len(t.width))
+	for k := range js {
+		js[k] = a.padding.justKindAt(a.textIndex(k), a.cells)
+	}
+	t.SetJustifies(js)
+	return t
+}
+
+func writeLines(bw *bufio.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (a *Aligner) flushMarkdown(bw *bufio.Writer) error {
+	return writeLines(bw, FormatMarkdown(a.lines, a.measure))
+}
+
+func (a *Aligner) flushRecords(bw *bufio.Writer) error {
+	records := make([][]string, len(a.rows))
+	for i, r := range a.rows {
+		records[i] = r.cells
+		if a.textOnly {
+			records[i] = a.textCells(r.cells)
+		}
+	}
+	if err := WriteRecords(bw, a.output, records); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (a *Aligner) cellWidth(cell string) int {
+	w := 0
+	for _, line := range strings.Split(cell, "\n") {
+		if n := a.measure.StringWidth(line); n > w {
+			w = n
+		}
+	}
+	return w
+}
+
+func (a *Aligner) layouts() []*RowLayout {
+	base := a.indentWidth()
+	layouts := make([]*RowLayout, len(a.rows))
+	for i, r := range a.rows {
+		row, src := r.cells, r.text
+		pw := r.padding.Width()
+		l := &RowLayout{
+			Text:       src,
+			Indent:     src[:len(src)-len(strings.TrimLeft(src, " \t"))],
+			Cells:      []string{},
+			Delimiters: []string{},
+			Widths:     make([]int, len(row)),
+		}
+		for k, cell := range row {
+			if a.padding.delimited && k%2 != 0 {
+				l.Delimiters = append(l.Delimiters, cell)
+			} else {
+				l.Cells = append(l.Cells, cell)
+			}
+			if len(row) > 1 && k < len(pw) && !a.compact {
+				l.Widths[k] = pw[k]
+			} else {
+				l.Widths[k] = a.cellWidth(cell)
+			}
+		}
+		if len(row) > 1 {
+			first := SplitLines(row)[0]
+			l.Offsets = a.margin.Offsets(base, a.padCells(r.padding, first), first, a.columns)
+		} else {
+			l.Offsets = make([]int, len(row))
+		}
+		layouts[i] = l
+	}
+	return layouts
+}
+
+func (a *Aligner) flushLayouts(bw *bufio.Writer) error {
+	if err := WriteLayouts(bw, a.output, a.layouts()); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (a *Aligner) flushTable(bw *bufio.Writer) error {
+	if a.border != nil {
+		return writeLines(bw, a.table().RenderGrid(a.border))
+	}
+	return writeLines(bw, a.table().Render(a.output))
 }
 
 func (a *Aligner) Flush(w io.Writer) error {
+	a.roundWidths()
 	bw := bufio.NewWriter(w)
-	for _, row := range a.cells {
-		if _, err := bw.WriteString(a.format(row) + "\n"); err != nil {
-			return err
+	if a.markdown {
+		return a.flushMarkdown(bw)
+	}
+	switch {
+	case a.output == OutputCSV, a.output == OutputTSV:
+		return a.flushRecords(bw)
+	case a.output == OutputJSON, a.output == OutputJSONL:
+		return a.flushLayouts(bw)
+	case a.output != OutputText, a.border != nil:
+		return a.flushTable(bw)
+	}
+	for _, r := range a.rows {
+		for _, line := range a.rowLines(r.cells) {
+			if _, err := bw.WriteString(a.format(r.padding, line) + "\n"); err != nil {
+				return err
+			}
 		}
 	}
 	return bw.Flush()
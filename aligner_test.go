@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -252,6 +254,14 @@ ccccc = 100
 あいう     ＝ 壱十
 あいうえお ＝ 壱十百
 `[1:])},
+
+	{`=`, []byte("" +
+		"\x1b[31ma\x1b[0m = 1\n" +
+		"bbb \x1b[1m=\x1b[0m 10\n" +
+		"\x1b[32mccccc\x1b[0m = \x1b[32m100\x1b[0m\n"), []byte("" +
+		"\x1b[31ma\x1b[0m     = 1\n" +
+		"bbb   \x1b[1m=\x1b[0m 10\n" +
+		"\x1b[32mccccc\x1b[0m = \x1b[32m100\x1b[0m\n")},
 }
 
 func TestAlignFixed(t *testing.T) {
@@ -569,6 +579,34 @@ aaaaa = b     = ccccc = d     = eeeee = f     = 100
         あ ＝ 壱
     あいう ＝ 壱十
 あいうえお ＝ 壱十百
+`[1:])},
+
+	{`a`, ``, []byte(`
+apple 3 1.5
+banana 120 30
+cherry 45 0.25
+`[1:]), []byte(`
+apple    3  1.5
+banana 120   30
+cherry  45 0.25
+`[1:])},
+
+	{`l,t-1:r`, `=`, []byte(`
+a = bbbbb = 1
+aaa = b = 10
+aaaaa = bbb = 100
+`[1:]), []byte(`
+a     = bbbbb =   1
+aaa   = b     =  10
+aaaaa = bbb   = 100
+`[1:])},
+
+	{`r,0:l`, ``, []byte(`
+a 1 10
+bbb 100 1
+`[1:]), []byte(`
+a     1 10
+bbb 100  1
 `[1:])},
 }
 
@@ -588,3 +626,1057 @@ func TestAlignJustify(t *testing.T) {
 		testAlign(t, a, test.src, test.dst)
 	}
 }
+
+var alignMaxWidthTests = []struct {
+	maxWidth string
+	overflow string
+	delim    string
+	src      []byte
+	dst      []byte
+}{
+	{`8`, `truncate`, `=`, []byte(`
+name = Tom
+description = a very long description
+age = 17
+`[1:]), []byte(`
+name     = Tom
+descrip… = a very…
+age      = 17
+`[1:])},
+
+	{`0,0,8`, `wrap`, `=`, []byte(`
+name = Tom
+description = a very long description
+age = 17
+`[1:]), []byte(`
+name        = Tom
+description = a very
+              long
+              descript
+              ion
+age         = 17
+`[1:])},
+
+	{`6`, `ignore`, `=`, []byte(`
+name = Tom
+description = a very long description
+age = 17
+`[1:]), []byte(`
+name = Tom
+description = a very long description
+age  = 17
+`[1:])},
+
+	{`1`, `truncate`, `=>`, []byte(`
+a => 1
+bb => 22
+`[1:]), []byte(`
+a => 1
+b => 2
+`[1:])},
+
+	{`1`, `wrap`, `=>`, []byte(`
+a => 1
+bb => 22
+`[1:]), []byte(`
+a => 1
+b => 2
+b    2
+`[1:])},
+}
+
+func TestAlignMaxWidth(t *testing.T) {
+	for _, test := range alignMaxWidthTests {
+		opt := &Option{
+			Delimiter: test.delim,
+			MaxWidth:  test.maxWidth,
+			Overflow:  test.overflow,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+var alignWidthsTests = []struct {
+	minWidth int
+	widths   string
+	delim    string
+	src      []byte
+	dst      []byte
+	width    []int
+}{
+	{0, ``, `=`, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a   = 1
+bbb = 10
+`[1:]), []int{3, 1, 2}},
+
+	{0, `6,1,3`, `=`, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a      = 1
+bbb    = 10
+`[1:]), []int{6, 1, 3}},
+
+	{0, `2`, `=`, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a   = 1
+bbb = 10
+`[1:]), []int{3, 1, 2}},
+
+	{5, ``, ``, []byte(`
+a 1
+bbb 10
+`[1:]), []byte(`
+a     1
+bbb   10
+`[1:]), []int{5, 5}},
+	{5, ``, `=`, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a     = 1
+bbb   = 10
+`[1:]), []int{5, 1, 5}},
+
+	{0, `4,3`, `=`, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a    =   1
+bbb  =   10
+`[1:]), []int{4, 3, 2}},
+}
+
+func TestAlignWidths(t *testing.T) {
+	for _, test := range alignWidthsTests {
+		opt := &Option{
+			Delimiter: test.delim,
+			MinWidth:  test.minWidth,
+			Widths:    test.widths,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+		if width := a.SectionWidths(); !reflect.DeepEqual(width, [][]int{test.width}) {
+			t.Errorf("SectionWidths() = %v; want %v", width, [][]int{test.width})
+		}
+	}
+}
+
+var alignWidthModelTests = []struct {
+	model string
+	src   []byte
+	dst   []byte
+}{
+	{`grapheme`, []byte(`
+👨‍👩‍👧 family
+abcdef x
+`[1:]), []byte(`
+👨‍👩‍👧     family
+abcdef x
+`[1:])},
+
+	{`rune`, []byte(`
+👨‍👩‍👧 family
+abcdef x
+`[1:]), []byte(`
+👨‍👩‍👧 family
+abcdef x
+`[1:])},
+}
+
+func TestAlignWidthModel(t *testing.T) {
+	for _, test := range alignWidthModelTests {
+		opt := &Option{
+			WidthModel: test.model,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+var alignEastAsianTests = []struct {
+	eastAsian string
+	src       []byte
+	dst       []byte
+}{
+	{`narrow`, []byte(`
+→ right
+abc up
+`[1:]), []byte(`
+→   right
+abc up
+`[1:])},
+
+	{`wide`, []byte(`
+→ right
+abc up
+`[1:]), []byte(`
+→  right
+abc up
+`[1:])},
+}
+
+func TestAlignEastAsian(t *testing.T) {
+	for _, test := range alignEastAsianTests {
+		opt := &Option{
+			EastAsian: test.eastAsian,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+var alignFillTests = []struct {
+	justify    string
+	fill       string
+	fillCells  string
+	marginFill string
+	src        []byte
+	dst        []byte
+}{
+	{`l,-1:r`, `.`, ``, `.`, []byte(`
+Introduction 1
+Installation 3
+Usage 12
+`[1:]), []byte(`
+Introduction..1
+Installation..3
+Usage........12
+`[1:])},
+
+	{`l,-1:r`, `.`, `0`, ``, []byte(`
+Introduction 1
+Installation 3
+Usage 12
+`[1:]), []byte(`
+Introduction  1
+Installation  3
+Usage....... 12
+`[1:])},
+	{``, `.`, ``, ``, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a.. = 1
+bbb = 10
+`[1:])},
+
+	{``, ``, ``, `・`, []byte(`
+a = 1
+bbb = 10
+`[1:]), []byte(`
+a   = 1
+bbb = 10
+`[1:])},
+}
+
+func TestAlignFill(t *testing.T) {
+	for _, test := range alignFillTests {
+		opt := &Option{
+			Justify:    test.justify,
+			Fill:       test.fill,
+			FillCells:  test.fillCells,
+			MarginFill: test.marginFill,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+var alignDelimiterJustifyTests = []struct {
+	justify      string
+	delimJustify string
+	delim        string
+	src          []byte
+	dst          []byte
+}{
+	{``, `r`, `[+:]?=`, []byte(`
+a = 1
+bb += 2
+ccc := 3
+`[1:]), []byte(`
+a    = 1
+bb  += 2
+ccc := 3
+`[1:])},
+
+	{`r`, `l`, `=+>`, []byte(`
+a=>b ===>  c
+c ==>    d ==>e
+f===> g =>   h
+`[1:]), []byte(`
+a =>   b ===> c
+c ==>  d ==>  e
+f ===> g =>   h
+`[1:])},
+
+	{``, `c`, `=+>`, []byte(`
+a=>b ===>  c
+c ==>    d ==>e
+f===> g =>   h
+`[1:]), []byte(`
+a  =>  b ===> c
+c ==>  d ==>  e
+f ===> g  =>  h
+`[1:])},
+}
+
+func TestAlignDelimiterJustify(t *testing.T) {
+	for _, test := range alignDelimiterJustifyTests {
+		opt := &Option{
+			Delimiter:    test.delim,
+			UseRegexp:    true,
+			Justify:      test.justify,
+			DelimJustify: test.delimJustify,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+var alignMarginListTests = []struct {
+	margin string
+	delim  string
+	src    []byte
+	dst    []byte
+}{
+	{`0:1,1:1,2:1`, `[,=#]`, []byte(`
+a,b=c#d
+aaa,bbb=ccc#ddd
+`[1:]), []byte(`
+a  , b   = c    # d
+aaa, bbb = ccc  # ddd
+`[1:])},
+
+	{`,=0:1,==1:1,#=2:1`, `[,=#]`, []byte(`
+x=1#one
+yy,zz=2#two
+`[1:]), []byte(`
+x  = 1   # one
+yy, zz = 2    # two
+`[1:])},
+}
+
+func TestAlignMarginList(t *testing.T) {
+	for _, test := range alignMarginListTests {
+		opt := &Option{
+			Delimiter: test.delim,
+			UseRegexp: true,
+			Margin:    test.margin,
+		}
+		a, err := NewAligner(opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				opt, err)
+			continue
+		}
+
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+func TestAlignMarkdown(t *testing.T) {
+	src := []byte(`
+# Fruits
+
+| Name | Qty |
+|:----:|----:|
+| apple | 3 |
+| banana | 120 |
+
+name = apple
+`[1:])
+	dst := []byte(`
+# Fruits
+
+|  Name  | Qty |
+| :----: | --: |
+| apple  |   3 |
+| banana | 120 |
+
+name = apple
+`[1:])
+
+	opt := &Option{
+		Delimiter: `=`,
+		Markdown:  true,
+	}
+	a, err := NewAligner(opt)
+	if err != nil {
+		t.Fatalf("NewAligner(%#v) returns %q; want nil",
+			opt, err)
+	}
+	testAlign(t, a, src, dst)
+}
+
+var alignOptionTests = []struct {
+	opt *Option
+	src []byte
+	dst []byte
+}{
+	{&Option{Delimiter: `,`, Justify: `a`, Output: `markdown`}, []byte(`
+name,qty,note
+apple,3,red|green
+banana,120,yellow
+`[1:]), []byte(`
+| name   | qty | note       |
+| :----- | --: | :--------- |
+| apple  |   3 | red\|green |
+| banana | 120 | yellow     |
+`[1:])},
+
+	{&Option{Delimiter: `,`, Justify: `lr`, Output: `org`}, []byte(`
+name,qty
+apple,3
+`[1:]), []byte(`
+| name  | qty |
+|-------+-----|
+| apple |   3 |
+`[1:])},
+
+	{&Option{Delimiter: `,`, Border: `unicode`, EastAsian: `wide`}, []byte(`
+name,qty
+りんご,3
+`[1:]), []byte(`
++--------+-----+
+| name   | qty |
+| りんご | 3   |
++--------+-----+
+`[1:])},
+
+	{&Option{Delimiter: `,`, Output: `org`}, []byte(`
+x,a|b
+`[1:]), []byte(`
+| x | a\vert{}b |
+`[1:])},
+
+	{&Option{Delimiter: `=`, Output: `rst`}, []byte(`
+key = value
+
+a = 1
+bb = 22
+`[1:]), []byte(`
++-----+-------+
+| key | value |
++=====+=======+
+| a   | 1     |
++-----+-------+
+| bb  | 22    |
++-----+-------+
+`[1:])},
+
+	{&Option{Delimiter: `,`, Justify: `a`, Border: `unicode`, Header: true}, []byte(`
+name,qty
+りんご,3
+banana,120
+`[1:]), []byte(`
+┌────────┬─────┐
+│ name   │ qty │
+├────────┼─────┤
+│ りんご │   3 │
+│ banana │ 120 │
+└────────┴─────┘
+`[1:])},
+
+	{&Option{Delimiter: `=`, Border: `ascii`}, []byte(`
+a = 1
+
+bbb = 22
+`[1:]), []byte(`
++-----+----+
+| a   | 1  |
+| bbb | 22 |
++-----+----+
+`[1:])},
+
+	{&Option{Delimiter: `=`, Border: `ascii`, MaxWidth: `3`, Overflow: `wrap`, Header: true}, []byte(`
+abcde = 1
+f = 2
+`[1:]), []byte(`
++-----+---+
+| abc | 1 |
+| de  |   |
++-----+---+
+| f   | 2 |
++-----+---+
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, Output: `csv`}, []byte(`
+alice  = 1 # admin, owner
+
+bob    = 2 # guest
+`[1:]), []byte(`
+alice,=,1,#,"admin, owner"
+bob,=,2,#,guest
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, Output: `csv`, TextOnly: true}, []byte(`
+alice  = 1 # admin, owner
+bob    = 2 # guest
+`[1:]), []byte(`
+alice,1,"admin, owner"
+bob,2,guest
+`[1:])},
+
+	{&Option{Output: `tsv`}, []byte(`
+a  bb "c"
+`[1:]), []byte(`
+a	bb	"""c"""
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, Output: `jsonl`}, []byte(`
+  a = 1 # x
+
+  bbb = 22
+lonely
+`[1:]), []byte(`
+{"text":"  a = 1 # x","indent":"  ","cells":["a","1","x"],"delimiters":["=","#"],"widths":[3,1,2,1,1],"offsets":[2,6,8,11,13]}
+{"text":"","indent":"","cells":[""],"delimiters":[],"widths":[0],"offsets":[0]}
+{"text":"  bbb = 22","indent":"  ","cells":["bbb","22"],"delimiters":["="],"widths":[3,1,2],"offsets":[2,6,8]}
+{"text":"lonely","indent":"","cells":["lonely"],"delimiters":[],"widths":[6],"offsets":[0]}
+`[1:])},
+
+	{&Option{Input: `csv`}, []byte(`
+name,note,qty
+alice,"hello, world",3
+bob,"two
+lines",120
+"say ""hi""",x,7
+`[1:]), []byte(`
+name     note         qty
+alice    hello, world 3
+bob      two          120
+         lines
+say "hi" x            7
+`[1:])},
+
+	{&Option{Input: `csv`, Requote: true, Margin: `0:1`}, []byte(`
+name,note
+alice,"hello, world"
+"say ""hi""",x
+`[1:]), []byte(`
+name        , note
+alice       , "hello, world"
+"say ""hi""", x
+`[1:])},
+
+	{&Option{Input: `json`}, []byte(`
+[["a", "b"], ["ccc", 1.50], [true, null, {"x": [1, 2]}]]
+`[1:]), []byte(`
+a    b
+ccc  1.50
+true      {"x":[1,2]}
+`[1:])},
+
+	{&Option{Input: `jsonl`, Keys: `city,name`}, []byte(`
+{"name": "alice", "age": 30}
+{"age": 4, "name": "bob", "city": "Tokyo"}
+`[1:]), []byte(`
+city  name
+      alice
+Tokyo bob
+`[1:])},
+
+	{&Option{Input: `tsv`, Justify: `1:r`}, []byte(`
+a	1
+bb	"22"
+`[1:]), []byte(`
+a   1
+bb 22
+`[1:])},
+
+	{&Option{Input: `csv`, Output: `jsonl`, Compact: true}, []byte(`
+name,qty
+apple,3
+"a,b",10
+`[1:]), []byte(`
+{"text":"name,qty","indent":"","cells":["name","qty"],"delimiters":[],"widths":[4,3],"offsets":[0,5]}
+{"text":"apple,3","indent":"","cells":["apple","3"],"delimiters":[],"widths":[5,1],"offsets":[0,6]}
+{"text":"\"a,b\",10","indent":"","cells":["a,b","10"],"delimiters":[],"widths":[3,2],"offsets":[0,4]}
+`[1:])},
+
+	{&Option{Input: `csv`, Requote: true, Output: `jsonl`}, []byte(`
+a,bb
+ccc,d
+`[1:]), []byte(`
+{"text":"a,bb","indent":"","cells":["a","bb"],"delimiters":[","],"widths":[3,1,2],"offsets":[0,4,6]}
+{"text":"ccc,d","indent":"","cells":["ccc","d"],"delimiters":[","],"widths":[3,1,2],"offsets":[0,4,6]}
+`[1:])},
+
+	{&Option{Delimiter: `=`, Justify: `l,-1:r`}, []byte(`
+a = bbbbb = c
+aaa = b
+`[1:]), []byte(`
+a   = bbbbb = c
+aaa = b
+`[1:])},
+
+	{&Option{Delimiter: `=`, Justify: `l,-1:r`, Output: `markdown`}, []byte(`
+a = bbbbb = c
+aaa = b
+`[1:]), []byte(`
+| a   | bbbbb |   c |
+| :-- | :---- | --: |
+| aaa | b     |     |
+`[1:])},
+
+	{&Option{Input: `csv`, Output: `csv`}, []byte(`
+a,"two
+lines",c
+d,e,f
+`[1:]), []byte(`
+a,"two
+lines",c
+d,e,f
+`[1:])},
+
+	{&Option{Input: `csv`, Output: `jsonl`, Compact: true}, []byte(`
+a,"b
+c"
+`[1:]), []byte(`
+{"text":"a,\"b\nc\"","indent":"","cells":["a","b\nc"],"delimiters":[],"widths":[1,1],"offsets":[0,2]}
+`[1:])},
+
+	{&Option{Input: `csv`, Output: `jsonl`}, []byte(`
+a,"bb
+c",d
+`[1:]), []byte(`
+{"text":"a,\"bb\nc\",d","indent":"","cells":["a","bb\nc","d"],"delimiters":[],"widths":[1,2,1],"offsets":[0,2,5]}
+`[1:])},
+
+	{&Option{Cut: `auto`}, []byte(`
+  PID TTY          TIME CMD
+    1 ?        00:00:03 /sbin/init splash
+12345 pts/10   00:01:22 vim New York.txt
+`[1:]), []byte(`
+PID   TTY    TIME     CMD
+1     ?      00:00:03 /sbin/init splash
+12345 pts/10 00:01:22 vim New York.txt
+`[1:])},
+
+	{&Option{Cut: `1,9`, Margin: `2`}, []byte(`
+New York10
+Paris   2
+`[1:]), []byte(`
+New York  10
+Paris     2
+`[1:])},
+
+	{&Option{Gap: 2}, []byte(`
+City  Pop  Country
+New York  8336817  United States
+Tokyo  13960000  Japan
+`[1:]), []byte(`
+City      Pop       Country
+New York  8336817   United States
+Tokyo     13960000  Japan
+`[1:])},
+
+	{&Option{Gap: 2, Margin: `1`}, []byte(`
+New York  10
+Paris  2
+`[1:]), []byte(`
+New York 10
+Paris    2
+`[1:])},
+
+	{&Option{Compact: true}, []byte(`
+    a   = 1   # x
+    bbb = 22  # y
+`[1:]), []byte(`
+    a = 1 # x
+    bbb = 22 # y
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, Compact: true, Margin: `0:1`, Indent: `0`}, []byte(`
+    a   = 1   # x
+    bbb = 22  # y
+`[1:]), []byte(`
+a= 1# x
+bbb= 22# y
+`[1:])},
+
+	{&Option{Delimiter: `=`, Indent: `2`}, []byte(`
+	a = 1
+	bbb = 22
+`[1:]), []byte(`
+  a   = 1
+  bbb = 22
+`[1:])},
+	{&Option{Delimiter: `=`, Indent: `1`}, []byte(`
+    [user]
+    a = 1
+
+      bbb = 22
+`[1:]), []byte(`
+ [user]
+ a   = 1
+
+ bbb = 22
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, GrowOnly: true}, []byte(`
+name     = alice   # admin
+age      = 30      # years
+id = 7 # new
+`[1:]), []byte(`
+name     = alice   # admin
+age      = 30      # years
+id       = 7       # new
+`[1:])},
+
+	{&Option{Delimiter: `=`, GrowOnly: true}, []byte(`
+  a  = 1
+  bbbbb = 2
+`[1:]), []byte(`
+  a     = 1
+  bbbbb = 2
+`[1:])},
+
+	{&Option{Delimiter: `=`, Margin: `2`, GrowOnly: true}, []byte(`
+a    =  1
+b = 2
+`[1:]), []byte(`
+a    =  1
+b    =  2
+`[1:])},
+
+	{&Option{GrowOnly: true}, []byte(`
+日本    1
+a 2
+`[1:]), []byte(`
+日本    1
+a       2
+`[1:])},
+
+	{&Option{Delimiter: `=`, Justify: `r`, GrowOnly: true}, []byte(`
+a =     1
+bb = 22
+`[1:]), []byte(`
+ a =     1
+bb =    22
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, Column: `20,40`}, []byte(`
+CC = gcc # compiler
+CFLAGS = -O2 -Wall -Wextra -pedantic -fno-strict-aliasing # flags
+LDFLAGS = -lm # libs
+`[1:]), []byte(`
+CC                 = gcc               # compiler
+CFLAGS             = -O2 -Wall -Wextra -pedantic -fno-strict-aliasing # flags
+LDFLAGS            = -lm               # libs
+`[1:])},
+
+	{&Option{Delimiter: `=`, Column: `8`}, []byte(`
+  a = 1
+  bbbbbbbbb = 2
+`[1:]), []byte(`
+  a    = 1
+  bbbbbbbbb = 2
+`[1:])},
+
+	{&Option{Delimiter: `=`, Column: `8`, Justify: `r`}, []byte(`
+a = 1
+bb = 2
+`[1:]), []byte(`
+ a     = 1
+bb     = 2
+`[1:])},
+
+	{&Option{Column: `5,10,15`}, []byte(`
+a b c d
+`[1:]), []byte(`
+a   b    c    d
+`[1:])},
+
+	{&Option{Delimiter: `=`, Column: `8`, Output: `jsonl`}, []byte(`
+a = 1
+`[1:]), []byte(`
+{"text":"a = 1","indent":"","cells":["a","1"],"delimiters":["="],"widths":[1,1,1],"offsets":[0,7,9]}
+`[1:])},
+
+	{&Option{Delimiter: `=|#`, UseRegexp: true, Round: 4}, []byte(`
+    alice = 1 # admin
+    bob = 200 # guest
+`[1:]), []byte(`
+    alice = 1   #   admin
+    bob   = 200 #   guest
+`[1:])},
+
+	{&Option{Round: 4}, []byte(`
+a bb ccc
+dd e f
+`[1:]), []byte(`
+a   bb  ccc
+dd  e   f
+`[1:])},
+
+	{&Option{Delimiter: `=`, Round: 4}, []byte(`
+ab = 1
+`[1:]), []byte(`
+ab =    1
+`[1:])},
+
+	{&Option{Delimiter: `=`, MaxPad: 8}, []byte(`
+a = 1
+bb = 2
+a_very_long_variable_name = 3
+c = 4
+dd = 5
+`[1:]), []byte(`
+a  = 1
+bb = 2
+a_very_long_variable_name = 3
+c  = 4
+dd = 5
+`[1:])},
+
+	{&Option{Delimiter: `=`, MaxPad: 3}, []byte(`
+a = 1
+bbbb = 2
+ccccc = 3
+`[1:]), []byte(`
+a    = 1
+bbbb = 2
+ccccc = 3
+`[1:])},
+
+	{&Option{Delimiter: `=`}, []byte(`
+a = 1
+a_very_long_variable_name = 3
+`[1:]), []byte(`
+a                         = 1
+a_very_long_variable_name = 3
+`[1:])},
+
+	{&Option{Delimiter: `=`, GrowOnly: true, MaxPad: 1, Border: `ascii`}, []byte(`
+a        = 1
+bbb = 2
+`[1:]), []byte(`
++----------+---+
+| a        | 1 |
+| bbb      | 2 |
++----------+---+
+`[1:])},
+
+	{&Option{Input: `json`}, []byte(`
+[[], ["a", "b"]]
+`[1:]), []byte(`
+
+a b
+`[1:])},
+
+	{&Option{Input: `json`, Border: `ascii`}, []byte(`
+[{}]
+`[1:]), []byte(``)},
+
+	{&Option{Input: `json`}, []byte(`
+[{}]
+`[1:]), []byte(`
+
+`[1:])},
+}
+
+func TestAlignOption(t *testing.T) {
+	for _, test := range alignOptionTests {
+		a, err := NewAligner(test.opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				test.opt, err)
+			continue
+		}
+		testAlign(t, a, test.src, test.dst)
+	}
+}
+
+var alignOptionErrTests = []*Option{
+	{Border: `ascii`, Output: `rst`},
+	{FillCells: `0`},
+	{Auto: true, Delimiter: `=`},
+	{Auto: true, Gap: 2},
+	{Auto: true, Cut: `auto`},
+	{Auto: true, Input: `csv`},
+	{Round: -1},
+	{Delimiter: `[,=]`, UseRegexp: true, Margin: `,=0:1,==3:3`, Round: 4},
+	{MaxPad: -1},
+}
+
+func TestAlignOptionErr(t *testing.T) {
+	for _, opt := range alignOptionErrTests {
+		if _, err := NewAligner(opt); err == nil {
+			t.Errorf("NewAligner(%#v) returns nil; want err",
+				opt)
+		}
+	}
+}
+
+func TestAlignRequoteRoundTrip(t *testing.T) {
+	src := `
+name,note,n
+alice,"hello, world",1
+"say ""hi""",x,20
+`[1:]
+	a, err := NewAligner(&Option{Input: `csv`, Requote: true, Margin: `0:1`})
+	if err != nil {
+		t.Fatalf("NewAligner returns %q; want nil", err)
+	}
+	if err := a.ReadAll(strings.NewReader(src)); err != nil {
+		t.Fatalf("ReadAll(%q) returns %q; want nil", src, err)
+	}
+	var out bytes.Buffer
+	if err := a.Flush(&out); err != nil {
+		t.Fatalf("Flush returns %q; want nil", err)
+	}
+
+	expect, err := csv.NewReader(strings.NewReader(src)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv.ReadAll(%q) returns %q; want nil", src, err)
+	}
+	r := csv.NewReader(&out)
+	r.TrimLeadingSpace = true
+	actual, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("csv.ReadAll(%q) returns %q; want nil", out.String(), err)
+	}
+	for _, record := range actual {
+		for i := range record {
+			record[i] = strings.TrimRight(record[i], " ")
+		}
+	}
+	if !reflect.DeepEqual(actual, expect) {
+		t.Errorf("csv.ReadAll(%q) = %q; want %q", out.String(), actual, expect)
+	}
+}
+
+func TestAlignRequoteNewline(t *testing.T) {
+	src := "a,b\n\"x\ny\",z\n"
+	a, err := NewAligner(&Option{Input: `csv`, Requote: true})
+	if err != nil {
+		t.Fatalf("NewAligner returns %q; want nil", err)
+	}
+	if err := a.ReadAll(strings.NewReader(src)); err == nil {
+		t.Errorf("ReadAll(%q) returns nil; want err", src)
+	}
+}
+
+var alignAutoTests = []struct {
+	opt      *Option
+	src      []byte
+	dst      []byte
+	detected string
+}{
+	{&Option{Auto: true}, []byte(`
+a = 1
+bbb = 22
+`[1:]), []byte(`
+a   = 1
+bbb = 22
+`[1:]), "="},
+
+	{&Option{Auto: true}, []byte(`
+City  Pop
+New York  8336817
+`[1:]), []byte(`
+City      Pop
+New York  8336817
+`[1:]), "gap=2"},
+
+	{&Option{Auto: true, Margin: `1`}, []byte(`
+City  Pop
+New York  8336817
+`[1:]), []byte(`
+City     Pop
+New York 8336817
+`[1:]), "gap=2"},
+
+	{&Option{Auto: true, Count: 1}, []byte(`
+a: 1:2
+bbb: 22:3
+`[1:]), []byte(`
+a   : 1:2
+bbb : 22:3
+`[1:]), ":"},
+
+	{&Option{Auto: true}, []byte(`
+a 1
+bbb 22
+`[1:]), []byte(`
+a   1
+bbb 22
+`[1:]), "spaces"},
+}
+
+func TestAlignAuto(t *testing.T) {
+	for _, test := range alignAutoTests {
+		a, err := NewAligner(test.opt)
+		if err != nil {
+			t.Errorf("NewAligner(%#v) returns %q; want nil",
+				test.opt, err)
+			continue
+		}
+		testAlign(t, a, test.src, test.dst)
+		if d := a.DetectedDelimiter(); d != test.detected {
+			t.Errorf("DetectedDelimiter() = %q; want %q",
+				d, test.detected)
+		}
+	}
+}
+
+func TestAlignMaxPadSectionWidths(t *testing.T) {
+	opt := &Option{Delimiter: `=`, MaxPad: 8}
+	a, err := NewAligner(opt)
+	if err != nil {
+		t.Fatalf("NewAligner(%#v) returns %q; want nil",
+			opt, err)
+	}
+	for _, s := range []string{"a = 1", "a_very_long_variable_name = 3", "bb = 22"} {
+		a.AddRow(s)
+	}
+	expect := [][]int{{1, 1, 1}, {25, 1, 1}, {2, 1, 2}}
+	actual := a.SectionWidths()
+	if !reflect.DeepEqual(actual, expect) {
+		t.Errorf("SectionWidths() = %v; want %v", actual, expect)
+	}
+}
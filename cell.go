@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var cellIndexFormat = regexp.MustCompile(`^([td]?)(-?\d+)$`)
+
+type CellIndex struct {
+	kind  byte
+	index int
+}
+
+func ParseCellIndex(format string) (CellIndex, error) {
+	a := cellIndexFormat.FindStringSubmatch(format)
+	if a == nil {
+		return CellIndex{}, fmt.Errorf("cell: invalid index: %s", format)
+	}
+	i, err := strconv.Atoi(a[2])
+	if err != nil {
+		return CellIndex{}, err
+	}
+	c := CellIndex{index: i}
+	if a[1] != "" {
+		c.kind = a[1][0]
+	}
+	return c, nil
+}
+
+func ParseCellIndexes(format string) ([]CellIndex, error) {
+	if format == "" {
+		return nil, nil
+	}
+	var cs []CellIndex
+	for _, s := range strings.Split(format, ",") {
+		c, err := ParseCellIndex(s)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+func (c CellIndex) Match(i, n int, delimited bool) bool {
+	k, count := i, n
+	switch c.kind {
+	case 't':
+		if delimited {
+			if i%2 != 0 {
+				return false
+			}
+			k, count = i/2, (n+1)/2
+		}
+	case 'd':
+		if !delimited || i%2 == 0 {
+			return false
+		}
+		k, count = i/2, n/2
+	}
+
+	index := c.index
+	if index < 0 {
+		index += count
+	}
+	return k == index
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+var parseCellIndexesTests = []struct {
+	format string
+	cells  []CellIndex
+}{
+	{"", nil},
+	{"0", []CellIndex{{0, 0}}},
+	{"-1", []CellIndex{{0, -1}}},
+	{"t2", []CellIndex{{'t', 2}}},
+	{"d-1", []CellIndex{{'d', -1}}},
+	{"0,t1,d-2", []CellIndex{{0, 0}, {'t', 1}, {'d', -2}}},
+}
+
+func TestParseCellIndexes(t *testing.T) {
+	for _, test := range parseCellIndexesTests {
+		cells, err := ParseCellIndexes(test.format)
+		if err != nil {
+			t.Errorf("ParseCellIndexes(%q) returns %q; want nil",
+				test.format, err)
+			continue
+		}
+		if !reflect.DeepEqual(cells, test.cells) {
+			t.Errorf("ParseCellIndexes(%q) = %v; want %v",
+				test.format, cells, test.cells)
+		}
+	}
+}
+
+var parseCellIndexesErrTests = []string{
+	"a",
+	"x1",
+	"1,",
+	",1",
+	"t",
+	"1:r",
+	"--1",
+}
+
+func TestParseCellIndexesErr(t *testing.T) {
+	for _, format := range parseCellIndexesErrTests {
+		_, err := ParseCellIndexes(format)
+		if err == nil {
+			t.Errorf("ParseCellIndexes(%q) returns nil; want err",
+				format)
+		}
+	}
+}
+
+var cellIndexMatchTests = []struct {
+	cell      CellIndex
+	delimited bool
+	n         int
+	i         int
+	match     bool
+}{
+	{CellIndex{0, 1}, false, 3, 1, true},
+	{CellIndex{0, 1}, false, 3, 2, false},
+	{CellIndex{0, -1}, false, 3, 2, true},
+	{CellIndex{0, -1}, true, 5, 4, true},
+	{CellIndex{0, -3}, false, 3, 0, true},
+	{CellIndex{0, -4}, false, 3, 0, false},
+
+	{CellIndex{'t', 1}, true, 5, 2, true},
+	{CellIndex{'t', 1}, true, 5, 1, false},
+	{CellIndex{'t', -1}, true, 5, 4, true},
+	{CellIndex{'t', 1}, false, 5, 1, true},
+
+	{CellIndex{'d', 0}, true, 5, 1, true},
+	{CellIndex{'d', 1}, true, 5, 3, true},
+	{CellIndex{'d', -1}, true, 5, 3, true},
+	{CellIndex{'d', 0}, true, 5, 0, false},
+	{CellIndex{'d', 0}, false, 5, 1, false},
+}
+
+func TestCellIndexMatch(t *testing.T) {
+	for _, test := range cellIndexMatchTests {
+		expect := test.match
+		actual := test.cell.Match(test.i, test.n, test.delimited)
+		if actual != expect {
+			t.Errorf("%v.Match(%v, %v, %v) = %v; want %v",
+				test.cell, test.i, test.n, test.delimited, actual, expect)
+		}
+	}
+}
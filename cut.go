@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+)
+
+type Cutter struct {
+	auto      bool
+	positions []int
+	measure   *Measure
+}
+
+func NewCutter(format string) (*Cutter, error) {
+	c := &Cutter{measure: DefaultMeasure}
+	switch {
+	case format == "":
+		return nil, nil
+	case format == "auto":
+		c.auto = true
+		c.positions = []int{0}
+	default:
+		ns, ok := parseNumbers(format)
+		if !ok {
+			return nil, fmt.Errorf("cut: invalid format: %s", format)
+		}
+		c.positions = []int{0}
+		for _, n := range ns {
+			last := c.positions[len(c.positions)-1]
+			switch {
+			case n < 1 || n-1 < last:
+				return nil, fmt.Errorf("cut: invalid format: %s", format)
+			case n-1 > last:
+				c.positions = append(c.positions, n-1)
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c *Cutter) Detect(lines []string) {
+	var space []bool
+	for _, line := range lines {
+		col := 0
+		c.measure.each(line, func(t string, w int) bool {
+			for k := 0; k < w; k++ {
+				if col+k == len(space) {
+					space = append(space, true)
+				}
+				if t != " " {
+					space[col+k] = false
+				}
+			}
+			col += w
+			return true
+		})
+	}
+
+	c.positions = []int{0}
+	seen := false
+	for i := 1; i < len(space); i++ {
+		seen = seen || !space[i-1]
+		if seen && space[i-1] && !space[i] {
+			c.positions = append(c.positions, i)
+		}
+	}
+}
+
+func (c *Cutter) Cut(s string) []string {
+	a := make([]string, 0, len(c.positions))
+	beg, col, k := 0, 0, 1
+	i := 0
+	c.measure.each(s, func(t string, w int) bool {
+		if w > 0 && k < len(c.positions) && col >= c.positions[k] {
+			a = append(a, s[beg:i])
+			beg = i
+			for k < len(c.positions) && col >= c.positions[k] {
+				k++
+			}
+		}
+		i += len(t)
+		col += w
+		return true
+	})
+	a = append(a, s[beg:])
+	for i := range a {
+		a[i] = TrimSpace(a[i])
+	}
+	return a
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+var newCutterTests = []struct {
+	src       string
+	auto      bool
+	positions []int
+}{
+	{"auto", true, []int{0}},
+	{"1", false, []int{0}},
+	{"10", false, []int{0, 9}},
+	{"1,10,25", false, []int{0, 9, 24}},
+	{"1,10,10", false, []int{0, 9}},
+}
+
+func TestNewCutter(t *testing.T) {
+	for _, test := range newCutterTests {
+		c, err := NewCutter(test.src)
+		if err != nil {
+			t.Errorf("NewCutter(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		if c.auto != test.auto {
+			t.Errorf("NewCutter(%q).auto = %v; want %v",
+				test.src, c.auto, test.auto)
+		}
+		if !reflect.DeepEqual(c.positions, test.positions) {
+			t.Errorf("NewCutter(%q).positions = %v; want %v",
+				test.src, c.positions, test.positions)
+		}
+	}
+}
+
+func TestNewCutterEmpty(t *testing.T) {
+	c, err := NewCutter("")
+	if c != nil || err != nil {
+		t.Errorf("NewCutter(%q) = %v, %v; want nil, nil",
+			"", c, err)
+	}
+}
+
+var newCutterErrTests = []string{
+	"0",
+	"10,5",
+	"1,,2",
+	"-1",
+	"AUTO",
+}
+
+func TestNewCutterErr(t *testing.T) {
+	for _, src := range newCutterErrTests {
+		if _, err := NewCutter(src); err == nil {
+			t.Errorf("NewCutter(%q) returns nil; want err",
+				src)
+		}
+	}
+}
+
+var cutterCutTests = []struct {
+	format string
+	src    string
+	dst    []string
+}{
+	{"1,5", "abc def", []string{"abc", "def"}},
+	{"1,6", "abc def", []string{"abc d", "ef"}},
+	{"1,5", "abc", []string{"abc"}},
+	{"1,5,9", "New York  10", []string{"New", "York", "10"}},
+	{"1,5", "  a     b", []string{"a", "b"}},
+	{"1,3", "日本語", []string{"日", "本語"}},
+	{"1,2", "日本語", []string{"日", "本語"}},
+	{"1,3", "\x1b[31mab\x1b[0mcd", []string{"\x1b[31mab\x1b[0m", "cd"}},
+}
+
+func TestCutterCut(t *testing.T) {
+	for _, test := range cutterCutTests {
+		c, err := NewCutter(test.format)
+		if err != nil {
+			t.Errorf("NewCutter(%q) returns %q; want nil",
+				test.format, err)
+			continue
+		}
+		expect := test.dst
+		actual := c.Cut(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("%q: Cut(%q) = %q; want %q",
+				test.format, test.src, actual, expect)
+		}
+	}
+}
+
+var cutterDetectTests = []struct {
+	src       []string
+	positions []int
+}{
+	{[]string{}, []int{0}},
+	{[]string{"a b"}, []int{0, 2}},
+	{[]string{"New York  10", "Paris     2"}, []int{0, 10}},
+	{[]string{"  PID TTY", "12345 pts/0", "    1 ?"}, []int{0, 6}},
+	{[]string{"ab  c", "", "a   d"}, []int{0, 4}},
+	{[]string{"日本 a", "x    b"}, []int{0, 5}},
+}
+
+func TestCutterDetect(t *testing.T) {
+	for _, test := range cutterDetectTests {
+		c, _ := NewCutter("auto")
+		c.Detect(test.src)
+		expect := test.positions
+		actual := c.positions
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("Detect(%q) = %v; want %v",
+				test.src, actual, expect)
+		}
+	}
+}
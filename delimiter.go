@@ -1,14 +1,15 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
-	"strings"
 )
 
 var Spaces = regexp.MustCompile(`\s+`)
 
 type Delimiter struct {
 	re    *regexp.Regexp
+	gap   *regexp.Regexp
 	count int
 }
 
@@ -39,14 +40,32 @@ func NewDelimiter(expr string, useRegexp bool, count int) (d *Delimiter, err err
 	return d, nil
 }
 
+func (d *Delimiter) SetGap(n int) error {
+	switch {
+	case n < 1:
+		return fmt.Errorf("delimiter: invalid gap: %d", n)
+	case d.re != nil:
+		return fmt.Errorf("delimiter: gap cannot be used with DELIM")
+	}
+	d.gap = regexp.MustCompile(fmt.Sprintf(`[ \t]{%d,}`, n))
+	return nil
+}
+
+func (d *Delimiter) HasDelimiterCells() bool {
+	return d.re != nil
+}
+
 func (d *Delimiter) Split(s string) []string {
+	if d.re == nil && d.gap != nil {
+		return d.gap.Split(s, d.count)
+	}
 	if d.re == nil {
 		return Spaces.Split(s, d.count)
 	}
 
-	matches := d.re.FindAllStringIndex(s, d.count)
+	matches := FindAllStringIndex(d.re, s, d.count)
 	if len(matches) == 0 {
-		return []string{strings.TrimSpace(s)}
+		return []string{TrimSpace(s)}
 	}
 
 	a := make([]string, 0, len(matches)*2+1)
@@ -69,7 +88,7 @@ func (d *Delimiter) Split(s string) []string {
 	}
 	a = append(a, s[beg:])
 	for i := 0; i < len(a); i++ {
-		a[i] = strings.TrimSpace(a[i])
+		a[i] = TrimSpace(a[i])
 	}
 	return a
 }
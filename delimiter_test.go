@@ -105,6 +105,13 @@ var delimiterSplitTests = []struct {
 		[]string{"a", "=>", "b", "==>", "c", "===>", "d"}},
 	{true, `=+>`, "a=>b==>=c",
 		[]string{"a", "=>", "b", "==>", "=c"}},
+
+	// escape sequences
+	{false, `=`, "\x1b[31mn\x1b[0m = 100",
+		[]string{"\x1b[31mn\x1b[0m", "=", "100"}},
+	{false, `=`, "n \x1b[1m=\x1b[0m 100",
+		[]string{"n", "\x1b[1m=\x1b[0m", "100"}},
+	{false, `m`, "\x1b[31mn\x1b[0m", []string{"\x1b[31mn\x1b[0m"}},
 }
 
 func TestDelimiterSplit(t *testing.T) {
@@ -170,3 +177,51 @@ func TestSplitWithCount(t *testing.T) {
 		}
 	}
 }
+
+var delimiterSplitWithGapTests = []struct {
+	gap   int
+	count int
+	src   string
+	dst   []string
+}{
+	{2, -1, "a b", []string{"a b"}},
+	{2, -1, "New York  10", []string{"New York", "10"}},
+	{2, -1, "a b  c\t\td   e", []string{"a b", "c", "d", "e"}},
+	{3, -1, "a  b   c", []string{"a  b", "c"}},
+	{1, -1, "a b", []string{"a", "b"}},
+	{2, 1, "a  b  c", []string{"a", "b  c"}},
+}
+
+func TestDelimiterSplitWithGap(t *testing.T) {
+	for _, test := range delimiterSplitWithGapTests {
+		d, err := NewDelimiter("", false, test.count)
+		if err != nil {
+			t.Errorf("NewDelimiter(%q, %v, %v) returns %q; want nil",
+				"", false, test.count, err)
+			continue
+		}
+		if err := d.SetGap(test.gap); err != nil {
+			t.Errorf("SetGap(%v) returns %q; want nil",
+				test.gap, err)
+			continue
+		}
+
+		expect := test.dst
+		actual := d.Split(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("SetGap(%v).Split(%q) = %q; want %q",
+				test.gap, test.src, actual, expect)
+		}
+	}
+}
+
+func TestDelimiterSetGapErr(t *testing.T) {
+	d, _ := NewDelimiter("", false, -1)
+	if err := d.SetGap(0); err == nil {
+		t.Errorf("SetGap(%v) returns nil; want err", 0)
+	}
+	d, _ = NewDelimiter("=", false, -1)
+	if err := d.SetGap(2); err == nil {
+		t.Errorf("SetGap(%v) with DELIM returns nil; want err", 2)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"regexp"
+)
+
+var autoSampleSize = 100
+
+type DelimiterCandidate struct {
+	Name string
+	expr string
+	gap  int
+	re   *regexp.Regexp
+}
+
+var delimiterCandidates = []*DelimiterCandidate{
+	{Name: "=>", expr: "=>"},
+	{Name: "//", expr: "//"},
+	{Name: "=", expr: "="},
+	{Name: ":", expr: ":"},
+	{Name: ",", expr: ","},
+	{Name: "|", expr: "|"},
+	{Name: "tab", expr: "\t"},
+	{Name: "gap=2", gap: 2},
+}
+
+func init() {
+	for _, c := range delimiterCandidates {
+		if c.gap > 0 {
+			c.re = regexp.MustCompile(`[ \t]{2,}`)
+		} else {
+			c.re = regexp.MustCompile(regexp.QuoteMeta(c.expr))
+		}
+	}
+}
+
+func sampleLines(lines []string) []string {
+	var a []string
+	for _, line := range lines {
+		if len(a) >= autoSampleSize {
+			break
+		}
+		if s := TrimSpace(line); s != "" {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+func (c *DelimiterCandidate) score(lines []string) int {
+	counts := make(map[int]int)
+	for _, s := range lines {
+		counts[len(FindAllStringIndex(c.re, s, -1))]++
+	}
+	best := 0
+	for n, lines := range counts {
+		if n > 0 && lines > best {
+			best = lines
+		}
+	}
+	return best
+}
+
+func DetectDelimiter(lines []string) *DelimiterCandidate {
+	lines = sampleLines(lines)
+	var best *DelimiterCandidate
+	bestScore := 0
+	for _, c := range delimiterCandidates {
+		if s := c.score(lines); s > bestScore {
+			best, bestScore = c, s
+		}
+	}
+	if bestScore*2 < len(lines) {
+		return nil
+	}
+	return best
+}
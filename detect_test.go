@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+var detectDelimiterTests = []struct {
+	src []string
+	dst string
+}{
+	{[]string{"a = 1", "bbb = 22"}, "="},
+	{[]string{"a => 1", "bbb => 22"}, "=>"},
+	{[]string{"a: 1", "bbb: 22", "", "c 3"}, ":"},
+	{[]string{"a,b,c", "d,e,f", "g,h"}, ","},
+	{[]string{"| a | b |", "| c | d |"}, "|"},
+	{[]string{"a\tb", "cc\tdd"}, "tab"},
+	{[]string{"New York  10", "Paris  2"}, "gap=2"},
+	{[]string{"a = 1", "b = 2", "c: 3, 4"}, "="},
+	{[]string{"a b c", "d e f"}, "spaces"},
+	{[]string{"a = 1", "b", "c", "d"}, "spaces"},
+	{[]string{}, "spaces"},
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	for _, test := range detectDelimiterTests {
+		expect := test.dst
+		actual := "spaces"
+		if c := DetectDelimiter(test.src); c != nil {
+			actual = c.Name
+		}
+		if actual != expect {
+			t.Errorf("DetectDelimiter(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+func TestDetectDelimiterSample(t *testing.T) {
+	src := make([]string, autoSampleSize*2)
+	for i := range src {
+		src[i] = "a b"
+		if i < autoSampleSize {
+			src[i] = "a = b"
+		}
+	}
+	c := DetectDelimiter(src)
+	if c == nil || c.Name != "=" {
+		t.Errorf("DetectDelimiter(%d lines) = %v; want %q",
+			len(src), c, "=")
+	}
+}
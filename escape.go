@@ -0,0 +1,106 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var EscapeSequence = regexp.MustCompile(
+	"\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(\x07|\x1b\\\\)")
+
+var selectGraphicRendition = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+const sgrReset = "\x1b[0m"
+
+func hasEscape(s string) bool {
+	return strings.IndexByte(s, '\x1b') != -1
+}
+
+func StripEscape(s string) string {
+	if !hasEscape(s) {
+		return s
+	}
+	return EscapeSequence.ReplaceAllString(s, "")
+}
+
+func stripEscapeWithIndex(s string) (string, []int) {
+	b := make([]byte, 0, len(s))
+	index := make([]int, 0, len(s))
+	beg := 0
+	for _, m := range EscapeSequence.FindAllStringIndex(s, -1) {
+		for i := beg; i < m[0]; i++ {
+			b = append(b, s[i])
+			index = append(index, i)
+		}
+		beg = m[1]
+	}
+	for i := beg; i < len(s); i++ {
+		b = append(b, s[i])
+		index = append(index, i)
+	}
+	return string(b), index
+}
+
+func TrimSpace(s string) string {
+	if !hasEscape(s) {
+		return strings.TrimSpace(s)
+	}
+
+	t, index := stripEscapeWithIndex(s)
+	beg := len(t) - len(strings.TrimLeftFunc(t, unicode.IsSpace))
+	end := len(strings.TrimRightFunc(t, unicode.IsSpace))
+	if beg > end {
+		beg = end
+	}
+	drop := make(map[int]bool)
+	for i := 0; i < beg; i++ {
+		drop[index[i]] = true
+	}
+	for i := end; i < len(t); i++ {
+		drop[index[i]] = true
+	}
+
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if !drop[i] {
+			b = append(b, s[i])
+		}
+	}
+	return string(b)
+}
+
+func FindAllStringIndex(re *regexp.Regexp, s string, n int) [][]int {
+	if !hasEscape(s) {
+		return re.FindAllStringIndex(s, n)
+	}
+
+	t, index := stripEscapeWithIndex(s)
+	matches := re.FindAllStringIndex(t, n)
+	for _, match := range matches {
+		if match[0] == match[1] {
+			if match[0] < len(index) {
+				match[0] = index[match[0]]
+			} else {
+				match[0] = len(s)
+			}
+			match[1] = match[0]
+			continue
+		}
+		beg, end := index[match[0]], index[match[1]-1]+1
+		switch {
+		case match[0] == 0:
+			beg = 0
+		case unicode.IsSpace(rune(t[match[0]-1])):
+			beg = index[match[0]-1] + 1
+		}
+		switch {
+		case match[1] == len(t):
+			end = len(s)
+		case unicode.IsSpace(rune(t[match[1]])):
+			end = index[match[1]]
+		}
+		match[0], match[1] = beg, end
+	}
+	return matches
+}
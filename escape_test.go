@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+var stripEscapeTests = []struct {
+	src string
+	dst string
+}{
+	{"abc", "abc"},
+	{"\x1b[31mabc\x1b[0m", "abc"},
+	{"\x1b[1;32mab\x1b[mc", "abc"},
+	{"a\x1b[Kbc", "abc"},
+	{"\x1b]8;;http://example.com\x07abc\x1b]8;;\x07", "abc"},
+	{"\x1b]0;title\x1b\\abc", "abc"},
+	{"\x1b[01;34m日本語\x1b[0m", "日本語"},
+}
+
+func TestStripEscape(t *testing.T) {
+	for _, test := range stripEscapeTests {
+		expect := test.dst
+		actual := StripEscape(test.src)
+		if actual != expect {
+			t.Errorf("StripEscape(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var trimSpaceTests = []struct {
+	src string
+	dst string
+}{
+	{"  abc  ", "abc"},
+	{" \x1b[31m abc \x1b[0m ", "\x1b[31mabc\x1b[0m"},
+	{"\x1b[31m a b \x1b[0m", "\x1b[31ma b\x1b[0m"},
+	{" \x1b[31m \x1b[0m ", "\x1b[31m\x1b[0m"},
+}
+
+func TestTrimSpace(t *testing.T) {
+	for _, test := range trimSpaceTests {
+		expect := test.dst
+		actual := TrimSpace(test.src)
+		if actual != expect {
+			t.Errorf("TrimSpace(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var findAllStringIndexTests = []struct {
+	expr string
+	src  string
+	dst  [][]int
+}{
+	{`=`, "a=b", [][]int{{1, 2}}},
+	{`=`, "\x1b[31ma\x1b[0m=b", [][]int{{10, 11}}},
+	{`=`, "a\x1b[31m=\x1b[0mb", [][]int{{6, 7}}},
+	{`==`, "a=\x1b[31m=b", [][]int{{1, 8}}},
+	{`\d+`, "\x1b[1m12\x1b[0m", [][]int{{0, 10}}},
+	{`m`, "\x1b[31ma\x1b[0m", nil},
+	{`=`, "a \x1b[1m=\x1b[0m b", [][]int{{2, 11}}},
+	{`=`, "\x1b[1m=\x1b[0m", [][]int{{0, 9}}},
+}
+
+func TestFindAllStringIndex(t *testing.T) {
+	for _, test := range findAllStringIndexTests {
+		re := regexp.MustCompile(test.expr)
+
+		expect := test.dst
+		actual := FindAllStringIndex(re, test.src, -1)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("FindAllStringIndex(%q, %q) = %v; want %v",
+				test.expr, test.src, actual, expect)
+		}
+	}
+}
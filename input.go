@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Input int
+
+const (
+	InputText Input = iota
+	InputCSV
+	InputTSV
+	InputJSON
+	InputJSONL
+)
+
+func ParseInput(s string) (Input, error) {
+	switch s {
+	case "", "text":
+		return InputText, nil
+	case "csv":
+		return InputCSV, nil
+	case "tsv":
+		return InputTSV, nil
+	case "json":
+		return InputJSON, nil
+	case "jsonl":
+		return InputJSONL, nil
+	default:
+		return 0, fmt.Errorf("input: invalid format: %s", s)
+	}
+}
+
+func (in Input) separator() string {
+	if in == InputTSV {
+		return "\t"
+	}
+	return ","
+}
+
+func quoteField(field string, sep string) string {
+	if field == "" || !strings.ContainsAny(field, sep+"\"\r\n") &&
+		field[0] != ' ' && field[0] != '\t' {
+		return field
+	}
+	return `"` + strings.Replace(field, `"`, `""`, -1) + `"`
+}
+
+func RecordCells(record []string, sep string, requote bool) ([]string, error) {
+	if len(record) == 0 {
+		return []string{""}, nil
+	}
+	row := make([]string, 0, len(record)*2)
+	for i, field := range record {
+		if requote {
+			if strings.ContainsAny(field, "\r\n") {
+				return nil, fmt.Errorf("input: cannot requote a field with newlines: %q", field)
+			}
+			field = quoteField(field, sep)
+			if i > 0 {
+				row = append(row, sep)
+			}
+		}
+		row = append(row, field)
+	}
+	return row, nil
+}
+
+func SplitLines(row []string) [][]string {
+	cells := make([][]string, len(row))
+	n := 1
+	for i, cell := range row {
+		cells[i] = strings.Split(cell, "\n")
+		if len(cells[i]) > n {
+			n = len(cells[i])
+		}
+	}
+	if n == 1 {
+		return [][]string{row}
+	}
+
+	rows := make([][]string, n)
+	for k := range rows {
+		line := make([]string, len(row))
+		for i, lines := range cells {
+			if k < len(lines) {
+				line[i] = lines[k]
+			}
+		}
+		rows[k] = line
+	}
+	return rows
+}
+
+func RowText(row []string, sep string, requote bool) string {
+	if requote {
+		return strings.Join(row, "")
+	}
+	a := make([]string, len(row))
+	for i, cell := range row {
+		a[i] = quoteField(cell, sep)
+	}
+	return strings.Join(a, sep)
+}
+
+func ReadRecords(r io.Reader, in Input, f func(record []string) error) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	if in == InputTSV {
+		cr.Comma = '\t'
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(record); err != nil {
+			return err
+		}
+	}
+}
+
+func ParseKeys(format string) []string {
+	if format == "" {
+		return nil
+	}
+	return strings.Split(format, ",")
+}
+
+func decodeElements(r io.Reader, in Input) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(r)
+	var a []json.RawMessage
+	if in == InputJSON {
+		if err := dec.Decode(&a); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+	for {
+		var m json.RawMessage
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, m)
+	}
+}
+
+func decodeObject(b []byte) ([]string, map[string]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	var keys []string
+	values := make(map[string]json.RawMessage)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := t.(string)
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = v
+	}
+	return keys, values, nil
+}
+
+func jsonCell(b json.RawMessage) string {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return ""
+	}
+	switch b[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err == nil {
+			return s
+		}
+	case '[', '{':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err == nil {
+			return buf.String()
+		}
+	case 'n':
+		return ""
+	}
+	return string(b)
+}
+
+func ReadJSON(r io.Reader, in Input, keys []string, f func(record []string) error) error {
+	elems, err := decodeElements(r, in)
+	if err != nil {
+		return err
+	}
+
+	type object map[string]json.RawMessage
+	rows := make([]interface{}, len(elems))
+	hasObject := false
+	seen := make(map[string]bool)
+	userKeys := keys != nil
+	for i, elem := range elems {
+		elem = bytes.TrimSpace(elem)
+		switch {
+		case len(elem) > 0 && elem[0] == '[':
+			var a []json.RawMessage
+			if err := json.Unmarshal(elem, &a); err != nil {
+				return err
+			}
+			record := make([]string, len(a))
+			for k, v := range a {
+				record[k] = jsonCell(v)
+			}
+			rows[i] = record
+		case len(elem) > 0 && elem[0] == '{':
+			ks, values, err := decodeObject(elem)
+			if err != nil {
+				return err
+			}
+			for _, key := range ks {
+				if !userKeys && !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+			rows[i] = object(values)
+			hasObject = true
+		default:
+			rows[i] = []string{jsonCell(elem)}
+		}
+	}
+
+	if hasObject && len(keys) > 0 {
+		if err := f(keys); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		var record []string
+		switch row := row.(type) {
+		case []string:
+			record = row
+		case object:
+			record = make([]string, len(keys))
+			for k, key := range keys {
+				if v, ok := row[key]; ok {
+					record[k] = jsonCell(v)
+				}
+			}
+		}
+		if err := f(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
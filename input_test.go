@@ -0,0 +1,317 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseInputTests = []struct {
+	src string
+	dst Input
+}{
+	{"", InputText},
+	{"text", InputText},
+	{"csv", InputCSV},
+	{"tsv", InputTSV},
+	{"json", InputJSON},
+	{"jsonl", InputJSONL},
+}
+
+func TestParseInput(t *testing.T) {
+	for _, test := range parseInputTests {
+		expect := test.dst
+		actual, err := ParseInput(test.src)
+		if err != nil {
+			t.Errorf("ParseInput(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		if actual != expect {
+			t.Errorf("ParseInput(%q) = %v; want %v",
+				test.src, actual, expect)
+		}
+	}
+}
+
+func TestParseInputError(t *testing.T) {
+	for _, src := range []string{"CSV", "xml", "ndjson"} {
+		if _, err := ParseInput(src); err == nil {
+			t.Errorf("ParseInput(%q) returns nil; want err",
+				src)
+		}
+	}
+}
+
+var quoteFieldTests = []struct {
+	src string
+	sep string
+	dst string
+}{
+	{"", ",", ""},
+	{"abc", ",", "abc"},
+	{"a,b", ",", `"a,b"`},
+	{"a,b", "\t", "a,b"},
+	{"a\tb", "\t", "\"a\tb\""},
+	{`say "hi"`, ",", `"say ""hi"""`},
+	{"a\nb", ",", "\"a\nb\""},
+	{" a", ",", `" a"`},
+}
+
+func TestQuoteField(t *testing.T) {
+	for _, test := range quoteFieldTests {
+		expect := test.dst
+		actual := quoteField(test.src, test.sep)
+		if actual != expect {
+			t.Errorf("quoteField(%q, %q) = %q; want %q",
+				test.src, test.sep, actual, expect)
+		}
+	}
+}
+
+var recordCellsTests = []struct {
+	record  []string
+	requote bool
+	dst     []string
+}{
+	{[]string{"a", "b"}, false, []string{"a", "b"}},
+	{[]string{"a", "b,c"}, true, []string{"a", ",", `"b,c"`}},
+	{[]string{"a", "b\nc"}, false, []string{"a", "b\nc"}},
+	{[]string{""}, false, []string{""}},
+	{[]string{}, false, []string{""}},
+}
+
+func TestRecordCells(t *testing.T) {
+	for _, test := range recordCellsTests {
+		expect := test.dst
+		actual, err := RecordCells(test.record, ",", test.requote)
+		if err != nil {
+			t.Errorf("RecordCells(%q, %v) returns %q; want nil",
+				test.record, test.requote, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("RecordCells(%q, %v) = %q; want %q",
+				test.record, test.requote, actual, expect)
+		}
+	}
+}
+
+var recordCellsErrTests = [][]string{
+	{"a", "b\nc"},
+	{"a\r", "b"},
+}
+
+func TestRecordCellsErr(t *testing.T) {
+	for _, record := range recordCellsErrTests {
+		if _, err := RecordCells(record, ",", true); err == nil {
+			t.Errorf("RecordCells(%q, true) returns nil; want err",
+				record)
+		}
+	}
+}
+
+var splitLinesTests = []struct {
+	row []string
+	dst [][]string
+}{
+	{[]string{"a", "b"}, [][]string{{"a", "b"}}},
+	{[]string{""}, [][]string{{""}}},
+	{
+		[]string{"a", "b\nc\nd", "e\nf"},
+		[][]string{{"a", "b", "e"}, {"", "c", "f"}, {"", "d", ""}},
+	},
+}
+
+func TestSplitLines(t *testing.T) {
+	for _, test := range splitLinesTests {
+		expect := test.dst
+		actual := SplitLines(test.row)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("SplitLines(%q) = %q; want %q",
+				test.row, actual, expect)
+		}
+	}
+}
+
+var rowTextTests = []struct {
+	row     []string
+	requote bool
+	dst     string
+}{
+	{[]string{"a", "b"}, false, "a,b"},
+	{[]string{"a", "b,c", ""}, false, `a,"b,c",`},
+	{[]string{"a", ",", `"b,c"`}, true, `a,"b,c"`},
+}
+
+func TestRowText(t *testing.T) {
+	for _, test := range rowTextTests {
+		expect := test.dst
+		actual := RowText(test.row, ",", test.requote)
+		if actual != expect {
+			t.Errorf("RowText(%q, %v) = %q; want %q",
+				test.row, test.requote, actual, expect)
+		}
+	}
+}
+
+var readRecordsTests = []struct {
+	input Input
+	src   string
+	dst   [][]string
+}{
+	{InputCSV, "a,b\nc,d\n", [][]string{{"a", "b"}, {"c", "d"}}},
+	{InputCSV, "a,\"b,c\"\n\"d\ne\",f\n", [][]string{{"a", "b,c"}, {"d\ne", "f"}}},
+	{InputCSV, "\"a\"\"b\"\n\nc,d,e\n", [][]string{{`a"b`}, {"c", "d", "e"}}},
+	{InputTSV, "a,b\tc\n", [][]string{{"a,b", "c"}}},
+}
+
+func TestReadRecords(t *testing.T) {
+	for _, test := range readRecordsTests {
+		var actual [][]string
+		err := ReadRecords(strings.NewReader(test.src), test.input, func(record []string) error {
+			actual = append(actual, record)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("ReadRecords(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		expect := test.dst
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("ReadRecords(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+func TestReadRecordsError(t *testing.T) {
+	src := "a,\"b\n"
+	err := ReadRecords(strings.NewReader(src), InputCSV, func([]string) error { return nil })
+	if err == nil {
+		t.Errorf("ReadRecords(%q) returns nil; want err",
+			src)
+	}
+}
+
+var parseKeysTests = []struct {
+	src string
+	dst []string
+}{
+	{"", nil},
+	{"a", []string{"a"}},
+	{"b,a", []string{"b", "a"}},
+}
+
+func TestParseKeys(t *testing.T) {
+	for _, test := range parseKeysTests {
+		expect := test.dst
+		actual := ParseKeys(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("ParseKeys(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var jsonCellTests = []struct {
+	src string
+	dst string
+}{
+	{`"a"`, "a"},
+	{`"a\nb"`, "a\nb"},
+	{`1.50`, "1.50"},
+	{`true`, "true"},
+	{`null`, ""},
+	{`[1, 2]`, "[1,2]"},
+	{`{"x": "y"}`, `{"x":"y"}`},
+}
+
+func TestJSONCell(t *testing.T) {
+	for _, test := range jsonCellTests {
+		expect := test.dst
+		actual := jsonCell([]byte(test.src))
+		if actual != expect {
+			t.Errorf("jsonCell(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var readJSONTests = []struct {
+	input Input
+	keys  []string
+	src   string
+	dst   [][]string
+}{
+	{
+		InputJSON, nil,
+		`[["a","b"],["c",1]]`,
+		[][]string{{"a", "b"}, {"c", "1"}},
+	},
+	{
+		InputJSON, nil,
+		`[{}]`,
+		[][]string{{}},
+	},
+	{
+		InputJSON, nil,
+		`[]`,
+		nil,
+	},
+	{
+		InputJSON, nil,
+		`[{"name":"alice","age":30},{"age":4,"name":"bob","city":"Tokyo"}]`,
+		[][]string{{"name", "age", "city"}, {"alice", "30", ""}, {"bob", "4", "Tokyo"}},
+	},
+	{
+		InputJSONL, nil,
+		"{\"b\":1,\"a\":2}\n\n[\"x\",\"y\"]\n\"z\"\n",
+		[][]string{{"b", "a"}, {"1", "2"}, {"x", "y"}, {"z"}},
+	},
+	{
+		InputJSONL, []string{"c", "a"},
+		"{\"a\":1,\"b\":2}\n{\"c\":3}\n",
+		[][]string{{"c", "a"}, {"", "1"}, {"3", ""}},
+	},
+}
+
+func TestReadJSON(t *testing.T) {
+	for _, test := range readJSONTests {
+		var actual [][]string
+		err := ReadJSON(strings.NewReader(test.src), test.input, test.keys, func(record []string) error {
+			actual = append(actual, record)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("ReadJSON(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		expect := test.dst
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("ReadJSON(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var readJSONErrTests = []struct {
+	input Input
+	src   string
+}{
+	{InputJSON, `{"a":1}`},
+	{InputJSON, `[["a"]`},
+	{InputJSONL, "[\"a\"]\n{\"b\":}\n"},
+}
+
+func TestReadJSONError(t *testing.T) {
+	for _, test := range readJSONErrTests {
+		err := ReadJSON(strings.NewReader(test.src), test.input, nil, func([]string) error { return nil })
+		if err == nil {
+			t.Errorf("ReadJSON(%q) returns nil; want err",
+				test.src)
+		}
+	}
+}
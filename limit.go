@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Overflow int
+
+const (
+	OverflowTruncate Overflow = iota
+	OverflowWrap
+	OverflowIgnore
+)
+
+var (
+	ellipsis              = "…"
+	commaSeparatedNumbers = regexp.MustCompile(`^\d+(,\d+)*$`)
+)
+
+func parseNumbers(format string) ([]int, bool) {
+	if !commaSeparatedNumbers.MatchString(format) {
+		return nil, false
+	}
+	a := strings.Split(format, ",")
+	ns := make([]int, len(a))
+	for i, s := range a {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, false
+		}
+		ns[i] = n
+	}
+	return ns, true
+}
+
+func ParseOverflow(s string) (Overflow, error) {
+	switch s {
+	case "", "truncate":
+		return OverflowTruncate, nil
+	case "wrap":
+		return OverflowWrap, nil
+	case "ignore":
+		return OverflowIgnore, nil
+	default:
+		return 0, fmt.Errorf("limit: invalid overflow: %s", s)
+	}
+}
+
+type Limit struct {
+	width     []int
+	overflow  Overflow
+	measure   *Measure
+	delimited bool
+}
+
+func NewLimit(format string, overflow string) (*Limit, error) {
+	l := &Limit{measure: DefaultMeasure}
+	o, err := ParseOverflow(overflow)
+	if err != nil {
+		return nil, err
+	}
+	l.overflow = o
+
+	if format == "" {
+		return l, nil
+	}
+	width, ok := parseNumbers(format)
+	if !ok {
+		return nil, fmt.Errorf("limit: invalid format: %s", format)
+	}
+	l.width = width
+	return l, nil
+}
+
+func (l *Limit) maxWidth(i int) int {
+	switch {
+	case len(l.width) == 0:
+		return 0
+	case i < len(l.width):
+		return l.width[i]
+	default:
+		return l.width[len(l.width)-1]
+	}
+}
+
+func (l *Limit) exceeds(i int, s string) bool {
+	if l.delimited && i%2 != 0 {
+		return false
+	}
+	w := l.maxWidth(i)
+	return w > 0 && l.measure.StringWidth(s) > w
+}
+
+func restEscapes(s string) string {
+	if !hasEscape(s) {
+		return ""
+	}
+	return strings.Join(EscapeSequence.FindAllString(s, -1), "")
+}
+
+func openEscapes(s string) string {
+	if !hasEscape(s) {
+		return ""
+	}
+	var open []string
+	for _, e := range selectGraphicRendition.FindAllString(s, -1) {
+		if e == sgrReset || e == "\x1b[m" {
+			open = open[:0]
+			continue
+		}
+		open = append(open, e)
+	}
+	return strings.Join(open, "")
+}
+
+func (l *Limit) truncate(s string, width int) string {
+	m := l.measure
+	ew := m.StringWidth(ellipsis)
+	if width <= ew {
+		i := m.CutIndex(s, width)
+		return s[:i] + restEscapes(s[i:])
+	}
+	i := m.CutIndex(s, width-ew)
+	return TrimSpace(s[:i]) + ellipsis + restEscapes(s[i:])
+}
+
+func (l *Limit) wrap(s string, width int) []string {
+	m := l.measure
+	var a []string
+	open := ""
+	for m.StringWidth(s) > width {
+		i := m.CutIndex(s, width)
+		if i == 0 {
+			i = m.FirstIndex(s)
+		}
+		if i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			if j := strings.LastIndexAny(s[:i], " \t"); j > 0 {
+				i = j
+			}
+		}
+		chunk := open + TrimSpace(s[:i])
+		open = openEscapes(open + s[:i])
+		if open != "" {
+			chunk += sgrReset
+		}
+		a = append(a, chunk)
+		s = TrimSpace(s[i:])
+	}
+	if s != "" {
+		a = append(a, open+s)
+	}
+	return a
+}
+
+func (l *Limit) Apply(a []string) [][]string {
+	if len(l.width) == 0 {
+		return [][]string{a}
+	}
+	switch l.overflow {
+	case OverflowTruncate:
+		b := make([]string, len(a))
+		for i, s := range a {
+			b[i] = s
+			if l.exceeds(i, s) {
+				b[i] = l.truncate(s, l.maxWidth(i))
+			}
+		}
+		return [][]string{b}
+	case OverflowWrap:
+		var lines [][]string
+		for i, s := range a {
+			chunks := []string{s}
+			if l.exceeds(i, s) {
+				chunks = l.wrap(s, l.maxWidth(i))
+			}
+			for j, chunk := range chunks {
+				if j == len(lines) {
+					lines = append(lines, make([]string, len(a)))
+				}
+				lines[j][i] = chunk
+			}
+		}
+		return lines
+	}
+	return [][]string{a}
+}
+
+func (l *Limit) Measured(a []string) []string {
+	if len(l.width) == 0 || l.overflow != OverflowIgnore {
+		return a
+	}
+	b := make([]string, len(a))
+	for i, s := range a {
+		if !l.exceeds(i, s) {
+			b[i] = s
+		}
+	}
+	return b
+}
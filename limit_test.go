@@ -0,0 +1,157 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+var limitSetTests = []struct {
+	format string
+	width  []int
+}{
+	{"", nil},
+	{"0", []int{0}},
+	{"10", []int{10}},
+	{"10,0,20", []int{10, 0, 20}},
+	{"005", []int{5}},
+}
+
+func TestLimitSet(t *testing.T) {
+	for _, test := range limitSetTests {
+		l, err := NewLimit(test.format, "")
+		if err != nil {
+			t.Errorf("NewLimit(%q, %q) returns %q; want nil",
+				test.format, "", err)
+			continue
+		}
+		if !reflect.DeepEqual(l.width, test.width) {
+			t.Errorf("NewLimit(%q, %q).width got %v; want %v",
+				test.format, "", l.width, test.width)
+		}
+	}
+}
+
+var limitSetErrTests = []struct {
+	format   string
+	overflow string
+}{
+	{"abc", ""},
+	{"-1", ""},
+	{"1,", ""},
+	{",1", ""},
+	{"1,,2", ""},
+	{"1:2", ""},
+	{"10", "cut"},
+	{"10", "Wrap"},
+}
+
+func TestLimitSetErr(t *testing.T) {
+	for _, test := range limitSetErrTests {
+		_, err := NewLimit(test.format, test.overflow)
+		if err == nil {
+			t.Errorf("NewLimit(%q, %q) returns nil; want err",
+				test.format, test.overflow)
+		}
+	}
+}
+
+var limitApplyTests = []struct {
+	format   string
+	overflow string
+	src      []string
+	dst      [][]string
+}{
+	// no limit
+	{"", "", []string{"abcdef", "="},
+		[][]string{{"abcdef", "="}}},
+	{"0", "", []string{"abcdef", "="},
+		[][]string{{"abcdef", "="}}},
+
+	// truncate
+	{"4", "truncate", []string{"abcdef", "=", "ab"},
+		[][]string{{"abc…", "=", "ab"}}},
+	{"4", "truncate", []string{"abcd", "=", "abcde"},
+		[][]string{{"abcd", "=", "abc…"}}},
+	{"0,0,3", "truncate", []string{"abcdef", "=", "abcdef"},
+		[][]string{{"abcdef", "=", "ab…"}}},
+	{"4", "truncate", []string{"ab cdef", "="},
+		[][]string{{"ab…", "="}}},
+	{"1", "truncate", []string{"abc", "="},
+		[][]string{{"a", "="}}},
+	{"5", "truncate", []string{"日本語", "="},
+		[][]string{{"日本…", "="}}},
+	{"4", "truncate", []string{"\x1b[1mab cdef\x1b[0m", "="},
+		[][]string{{"\x1b[1mab…\x1b[0m", "="}}},
+	{"1", "truncate", []string{"\x1b[1mabc\x1b[0m", "="},
+		[][]string{{"\x1b[1ma\x1b[0m", "="}}},
+
+	// wrap
+	{"5", "wrap", []string{"abc def ghi", "=", "x"},
+		[][]string{{"abc", "=", "x"}, {"def", "", ""}, {"ghi", "", ""}}},
+	{"5", "wrap", []string{"abcdefgh", "=", "x"},
+		[][]string{{"abcde", "=", "x"}, {"fgh", "", ""}}},
+	{"3", "wrap", []string{"a", "=", "bbbbbbb"},
+		[][]string{{"a", "=", "bbb"}, {"", "", "bbb"}, {"", "", "b"}}},
+	{"3", "wrap", []string{"aaaa", "=", "bbbbbbb"},
+		[][]string{{"aaa", "=", "bbb"}, {"a", "", "bbb"}, {"", "", "b"}}},
+	{"1", "wrap", []string{"日本", "="},
+		[][]string{{"日", "="}, {"本", ""}}},
+	{"0,0,5", "wrap", []string{"k", "=", "\x1b[31mhello world\x1b[0m", "=", "z"},
+		[][]string{{"k", "=", "\x1b[31mhello\x1b[0m", "=", "z"}, {"", "", "\x1b[31mworld\x1b[0m", "", ""}}},
+	{"3", "wrap", []string{"\x1b[1mab\x1b[0m cd"},
+		[][]string{{"\x1b[1mab\x1b[0m"}, {"cd"}}},
+
+	// ignore
+	{"3", "ignore", []string{"abcdef", "=", "x"},
+		[][]string{{"abcdef", "=", "x"}}},
+}
+
+func TestLimitApply(t *testing.T) {
+	for _, test := range limitApplyTests {
+		l, err := NewLimit(test.format, test.overflow)
+		if err != nil {
+			t.Errorf("NewLimit(%q, %q) returns %q; want nil",
+				test.format, test.overflow, err)
+			continue
+		}
+
+		expect := test.dst
+		actual := l.Apply(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("NewLimit(%q, %q).Apply(%q) = %q; want %q",
+				test.format, test.overflow, test.src, actual, expect)
+		}
+	}
+}
+
+var limitMeasuredTests = []struct {
+	format   string
+	overflow string
+	src      []string
+	dst      []string
+}{
+	{"3", "truncate", []string{"abcdef", "=", "x"},
+		[]string{"abcdef", "=", "x"}},
+	{"3", "ignore", []string{"abcdef", "=", "x"},
+		[]string{"", "=", "x"}},
+	{"0,0,3", "ignore", []string{"abcdef", "=", "abcd"},
+		[]string{"abcdef", "=", ""}},
+}
+
+func TestLimitMeasured(t *testing.T) {
+	for _, test := range limitMeasuredTests {
+		l, err := NewLimit(test.format, test.overflow)
+		if err != nil {
+			t.Errorf("NewLimit(%q, %q) returns %q; want nil",
+				test.format, test.overflow, err)
+			continue
+		}
+
+		expect := test.dst
+		actual := l.Measured(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("NewLimit(%q, %q).Measured(%q) = %q; want %q",
+				test.format, test.overflow, test.src, actual, expect)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/ogier/pflag"
 )
@@ -19,13 +21,42 @@ type CLI struct {
 	stdout io.Writer
 	stderr io.Writer
 
-	delimiter string
-	useRegexp bool
-	count     int
-	margin    string
-	justify   string
-	isHelp    bool
-	isVersion bool
+	delimiter     string
+	useRegexp     bool
+	count         int
+	margin        string
+	justify       string
+	delimJustify  string
+	maxWidth      string
+	overflow      string
+	minWidth      int
+	widths        string
+	widthModel    string
+	eastAsian     string
+	fill          string
+	fillCells     string
+	marginFill    string
+	isMarkdown    bool
+	output        string
+	border        string
+	isHeader      bool
+	isTextOnly    bool
+	input         string
+	isRequote     bool
+	keys          string
+	cut           string
+	gap           int
+	isAuto        bool
+	isVerbose     bool
+	isCompact     bool
+	indent        string
+	isGrowOnly    bool
+	column        string
+	round         int
+	maxPad        int
+	isPrintWidths bool
+	isHelp        bool
+	isVersion     bool
 }
 
 func NewCLI(stdin io.Reader, stdout io.Writer, stderr io.Writer) *CLI {
@@ -45,10 +76,50 @@ Delimiter control:
   -d, --delimiter=DELIM      separate lines by DELIM
   -r, --regexp               DELIM is a regular expression
   -c, --count=COUNT          separate lines only COUNT times
+      --gap=N                separate lines by N or more spaces without DELIM
+      --auto                 detect DELIM from the input
+      --verbose              report the detected DELIM to standard error
+      --input=FORMAT         read records in FORMAT instead of lines
+                             (FORMAT is text, csv, tsv, json, or jsonl)
+      --requote              quote fields of --input again, and keep separators
+      --keys=KEY[,KEY]...    read only KEYs of objects of --input in this order
+      --cut=N[,N]...|auto    separate lines at columns N instead of DELIM
 
 Output control:
   -m, --margin=N[:M]         put N or N and M spaces at both ends of DELIM
-  -j, --justify=[l|c|r]...   justify cells to the left, center, or right
+  -j, --justify=[l|c|r|a]...
+                             justify cells to the left, center, right, or auto
+      --delimiter-justify=[l|c|r|a]...
+                             justify delimiter cells independently of -j
+      --max-width=N[,N]...   limit the width of cells to N
+      --overflow=MODE        handle cells wider than the limit by MODE
+                             (MODE is truncate, wrap, or ignore)
+      --min-width=N          make the width of cells at least N
+      --widths=N[,N]...      make the width of each cell at least N
+      --print-widths         print the computed widths instead of lines
+      --width-model=MODEL    measure the width of cells by MODEL
+                             (MODEL is grapheme or rune)
+      --east-asian-width=auto|narrow|wide
+                             treat ambiguous width characters as narrow or wide
+      --fill=STR             pad cells with STR instead of spaces
+      --fill-cells=INDEX[,INDEX]...
+                             pad only the cells at INDEX with STR of --fill
+      --margin-fill=STR      put STR instead of spaces as margin
+      --markdown             reformat Markdown tables, and leave other lines
+      --compact              join cells only by margins without padding them
+      --indent=N             put N spaces instead of the shortest leading spaces
+      --grow-only            keep cells at least as wide as they are in the input
+      --column=N[,N]...      put delimiter cells at the columns N
+      --round=N              start cells at multiples of N
+      --max-pad=N            start a new section when cells need more than N
+                             spaces to be aligned
+      --output=FORMAT        print cells in FORMAT
+                             (FORMAT is text, markdown, org, rst, csv, tsv,
+                              json, or jsonl)
+      --text-only            leave out delimiter cells in csv and tsv
+      --border=STYLE         draw borders around cells in STYLE
+                             (STYLE is unicode or ascii)
+      --header               separate the first line from the others
 
 Miscellaneous:
   -h, --help                 display this help and exit
@@ -75,8 +146,37 @@ func (c *CLI) parseOption(args []string) (argFiles []string, err error) {
 	f.StringVarP(&c.delimiter, "delimiter", "d", "", "")
 	f.BoolVarP(&c.useRegexp, "regexp", "r", false, "")
 	f.IntVarP(&c.count, "count", "c", -1, "")
+	f.StringVarP(&c.input, "input", "", "", "")
+	f.BoolVarP(&c.isRequote, "requote", "", false, "")
+	f.StringVarP(&c.keys, "keys", "", "", "")
+	f.StringVarP(&c.cut, "cut", "", "", "")
+	f.IntVarP(&c.gap, "gap", "", 0, "")
+	f.BoolVarP(&c.isAuto, "auto", "", false, "")
+	f.BoolVarP(&c.isVerbose, "verbose", "", false, "")
 	f.StringVarP(&c.margin, "margin", "m", "", "")
 	f.StringVarP(&c.justify, "justify", "j", "", "")
+	f.StringVarP(&c.delimJustify, "delimiter-justify", "", "", "")
+	f.StringVarP(&c.maxWidth, "max-width", "", "", "")
+	f.StringVarP(&c.overflow, "overflow", "", "", "")
+	f.IntVarP(&c.minWidth, "min-width", "", 0, "")
+	f.StringVarP(&c.widths, "widths", "", "", "")
+	f.BoolVarP(&c.isPrintWidths, "print-widths", "", false, "")
+	f.StringVarP(&c.widthModel, "width-model", "", "", "")
+	f.StringVarP(&c.eastAsian, "east-asian-width", "", "", "")
+	f.StringVarP(&c.fill, "fill", "", "", "")
+	f.StringVarP(&c.fillCells, "fill-cells", "", "", "")
+	f.StringVarP(&c.marginFill, "margin-fill", "", "", "")
+	f.BoolVarP(&c.isMarkdown, "markdown", "", false, "")
+	f.BoolVarP(&c.isCompact, "compact", "", false, "")
+	f.StringVarP(&c.indent, "indent", "", "", "")
+	f.BoolVarP(&c.isGrowOnly, "grow-only", "", false, "")
+	f.StringVarP(&c.column, "column", "", "", "")
+	f.IntVarP(&c.round, "round", "", 0, "")
+	f.IntVarP(&c.maxPad, "max-pad", "", 0, "")
+	f.StringVarP(&c.output, "output", "", "", "")
+	f.StringVarP(&c.border, "border", "", "", "")
+	f.BoolVarP(&c.isHeader, "header", "", false, "")
+	f.BoolVarP(&c.isTextOnly, "text-only", "", false, "")
 	f.BoolVarP(&c.isHelp, "help", "h", false, "")
 	f.BoolVarP(&c.isVersion, "version", "", false, "")
 
@@ -87,12 +187,42 @@ func (c *CLI) parseOption(args []string) (argFiles []string, err error) {
 }
 
 func (c *CLI) newAligner() (a *Aligner, err error) {
+	if c.isVerbose && !c.isAuto {
+		return nil, fmt.Errorf("delimiter: verbose cannot be used without auto")
+	}
 	return NewAligner(&Option{
-		Delimiter: c.delimiter,
-		UseRegexp: c.useRegexp,
-		Count:     c.count,
-		Margin:    c.margin,
-		Justify:   c.justify,
+		Delimiter:    c.delimiter,
+		UseRegexp:    c.useRegexp,
+		Count:        c.count,
+		Margin:       c.margin,
+		Justify:      c.justify,
+		DelimJustify: c.delimJustify,
+		MaxWidth:     c.maxWidth,
+		Overflow:     c.overflow,
+		MinWidth:     c.minWidth,
+		Widths:       c.widths,
+		WidthModel:   c.widthModel,
+		EastAsian:    c.eastAsian,
+		Fill:         c.fill,
+		FillCells:    c.fillCells,
+		MarginFill:   c.marginFill,
+		Markdown:     c.isMarkdown,
+		Output:       c.output,
+		Border:       c.border,
+		Header:       c.isHeader,
+		TextOnly:     c.isTextOnly,
+		Input:        c.input,
+		Requote:      c.isRequote,
+		Keys:         c.keys,
+		Cut:          c.cut,
+		Gap:          c.gap,
+		Auto:         c.isAuto,
+		Compact:      c.isCompact,
+		Indent:       c.indent,
+		GrowOnly:     c.isGrowOnly,
+		Column:       c.column,
+		Round:        c.round,
+		MaxPad:       c.maxPad,
 	})
 }
 
@@ -113,10 +243,29 @@ func (c *CLI) newArgf(argFiles []string) (r io.Reader, err error) {
 	}
 }
 
+func (c *CLI) printWidths(widths [][]int) error {
+	for _, width := range widths {
+		a := make([]string, len(width))
+		for i, w := range width {
+			a[i] = strconv.Itoa(w)
+		}
+		if _, err := fmt.Fprintln(c.stdout, strings.Join(a, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *CLI) do(a *Aligner, r io.Reader) error {
 	if err := a.ReadAll(r); err != nil {
 		return err
 	}
+	if c.isVerbose {
+		fmt.Fprintf(c.stderr, "%s: detected delimiter: %s\n", cmdName, a.DetectedDelimiter())
+	}
+	if c.isPrintWidths {
+		return c.printWidths(a.SectionWidths())
+	}
 	if err := a.Flush(c.stdout); err != nil {
 		return err
 	}
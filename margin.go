@@ -5,40 +5,106 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 var (
 	digitOnly            = regexp.MustCompile(`^\d+$`)
 	colonSeparatedDigits = regexp.MustCompile(`^(\d+):(\d+)$`)
+	marginList           = regexp.MustCompile(`^\d+(:\d+)?(,\d+(:\d+)?)+$`)
+	keyedMargin          = regexp.MustCompile(`^(.+?)=(\d+(?::\d+)?)(?:,|$)`)
 )
 
-type Margin struct {
+func ParseColumns(format string) ([]int, error) {
+	if format == "" {
+		return nil, nil
+	}
+	columns, ok := parseNumbers(format)
+	if !ok {
+		return nil, fmt.Errorf("margin: invalid column: %s", format)
+	}
+	for _, n := range columns {
+		if n < 1 {
+			return nil, fmt.Errorf("margin: invalid column: %s", format)
+		}
+	}
+	return columns, nil
+}
+
+type marginPair struct {
 	left  int
 	right int
 }
 
-func NewMargin(format string) (*Margin, error) {
-	m := &Margin{}
+func parseMarginPair(format string) (marginPair, error) {
 	switch {
-	case format == "":
-		m.left, m.right = 1, 1
 	case digitOnly.MatchString(format):
 		n, err := strconv.Atoi(format)
 		if err != nil {
-			return nil, err
+			return marginPair{}, err
 		}
-		m.left, m.right = n, n
+		return marginPair{n, n}, nil
 	case colonSeparatedDigits.MatchString(format):
 		a := colonSeparatedDigits.FindAllStringSubmatch(format, -1)[0]
 		l, err := strconv.Atoi(a[1])
 		if err != nil {
-			return nil, err
+			return marginPair{}, err
 		}
 		r, err := strconv.Atoi(a[2])
+		if err != nil {
+			return marginPair{}, err
+		}
+		return marginPair{l, r}, nil
+	default:
+		return marginPair{}, fmt.Errorf("margin: invalid format: %s", format)
+	}
+}
+
+type Margin struct {
+	left      int
+	right     int
+	fill      string
+	pairs     []marginPair
+	keyed     map[string]marginPair
+	measure   *Measure
+	delimited bool
+}
+
+func NewMargin(format string) (*Margin, error) {
+	m := &Margin{fill: " ", measure: DefaultMeasure}
+	switch {
+	case format == "":
+		m.left, m.right = 1, 1
+	case digitOnly.MatchString(format), colonSeparatedDigits.MatchString(format):
+		p, err := parseMarginPair(format)
 		if err != nil {
 			return nil, err
 		}
-		m.left, m.right = l, r
+		m.left, m.right = p.left, p.right
+	case marginList.MatchString(format):
+		for _, s := range strings.Split(format, ",") {
+			p, err := parseMarginPair(s)
+			if err != nil {
+				return nil, err
+			}
+			m.pairs = append(m.pairs, p)
+		}
+		m.left, m.right = m.pairs[0].left, m.pairs[0].right
+	case strings.Contains(format, "="):
+		m.left, m.right = 1, 1
+		m.keyed = make(map[string]marginPair)
+		for s := format; s != ""; {
+			a := keyedMargin.FindStringSubmatch(s)
+			if a == nil || a[0] == s && strings.HasSuffix(s, ",") {
+				return nil, fmt.Errorf("margin: invalid format: %s", format)
+			}
+			p, err := parseMarginPair(a[2])
+			if err != nil {
+				return nil, err
+			}
+			m.keyed[a[1]] = p
+			s = s[len(a[0]):]
+		}
 	default:
 		return nil, fmt.Errorf("margin: invalid format: %s", format)
 	}
@@ -47,45 +113,154 @@ func NewMargin(format string) (*Margin, error) {
 
 func NewMarginWithNumber(left, right int) *Margin {
 	return &Margin{
-		left:  left,
-		right: right,
+		left:    left,
+		right:   right,
+		fill:    " ",
+		measure: DefaultMeasure,
 	}
 }
 
-func (m *Margin) Join(a []string) string {
-	switch len(a) {
-	case 0:
-		return ""
-	case 1:
-		return a[0]
-	}
+func (m *Margin) SetFill(fill string) {
+	m.fill = fill
+}
 
-	l, r := m.left, m.right
+func (m *Margin) pair(k int, delim string) (l, r int) {
+	switch {
+	case m.keyed != nil:
+		if p, ok := m.keyed[delim]; ok {
+			l, r = p.left, p.right
+		} else {
+			l, r = m.left, m.right
+		}
+	case len(m.pairs) > k:
+		l, r = m.pairs[k].left, m.pairs[k].right
+	case len(m.pairs) > 0:
+		l, r = m.pairs[len(m.pairs)-1].left, m.pairs[len(m.pairs)-1].right
+	default:
+		l, r = m.left, m.right
+	}
 	if l < 0 {
 		l = 0
 	}
 	if r < 0 {
 		r = 0
 	}
+	return l, r
+}
+
+func (m *Margin) Join(a []string) string {
+	return m.JoinWith(a, nil)
+}
 
-	buflen := (l + r) * (len(a) / 2)
-	if len(a)%2 == 0 {
-		buflen -= r
+func (m *Margin) JoinWith(a []string, delims []string) string {
+	switch len(a) {
+	case 0:
+		return ""
+	case 1:
+		return a[0]
 	}
+
+	buflen := 0
 	for i := 0; i < len(a); i++ {
 		buflen += len(a[i])
 	}
-	lm, rm := strings.Repeat(" ", l), strings.Repeat(" ", r)
-
-	b := make([]byte, buflen)
-	bp := copy(b, a[0])
+	b := make([]byte, 0, buflen)
+	b = append(b, a[0]...)
 	for i := 2; i <= len(a); i += 2 {
-		bp += copy(b[bp:], lm)
-		bp += copy(b[bp:], a[i-1])
+		delim := strings.TrimSpace(a[i-1])
+		if i-1 < len(delims) {
+			delim = delims[i-1]
+		}
+		l, r := m.pair(i/2-1, delim)
+
+		b = append(b, m.measure.Fill(m.fill, l)...)
+		b = append(b, a[i-1]...)
 		if i != len(a) {
-			bp += copy(b[bp:], rm)
-			bp += copy(b[bp:], a[i])
+			b = append(b, m.measure.Fill(m.fill, r)...)
+			b = append(b, a[i]...)
 		}
 	}
 	return string(b)
 }
+
+func (m *Margin) JoinAt(base int, a []string, delims []string, columns []int) string {
+	s, _ := m.joinAt(base, a, delims, columns)
+	return s
+}
+
+func (m *Margin) joinAt(base int, a []string, delims []string, columns []int) (string, []int) {
+	offsets := make([]int, len(a))
+	if len(a) == 0 {
+		return "", offsets
+	}
+	ms := m.measure
+	offsets[0] = base
+	b := []byte(a[0])
+	for i := 1; i < len(a); i++ {
+		n := m.gap(i, delims)
+		if k, ok := m.anchor(i); ok && k < len(columns) {
+			b = []byte(strings.TrimRightFunc(string(b), unicode.IsSpace))
+			pad := columns[k] - 1 - base - ms.StringWidth(string(b))
+			if pad < n {
+				pad = n
+			}
+			b = append(b, ms.Fill(m.fill, pad)...)
+		} else {
+			b = append(b, ms.Fill(m.fill, n)...)
+		}
+		offsets[i] = base + ms.StringWidth(string(b))
+		b = append(b, a[i]...)
+	}
+	return string(b), offsets
+}
+
+func (m *Margin) anchor(i int) (int, bool) {
+	if !m.delimited {
+		return i - 1, true
+	}
+	return i / 2, i%2 != 0
+}
+
+func (m *Margin) gap(i int, delims []string) int {
+	if i%2 != 0 {
+		delim := ""
+		if i < len(delims) {
+			delim = delims[i]
+		}
+		l, _ := m.pair(i/2, delim)
+		return l
+	}
+	delim := ""
+	if i-1 < len(delims) {
+		delim = delims[i-1]
+	}
+	_, r := m.pair(i/2-1, delim)
+	return r
+}
+
+func (m *Margin) Offsets(base int, a []string, delims []string, columns []int) []int {
+	_, offsets := m.joinAt(base, a, delims, columns)
+	return offsets
+}
+
+func (m *Margin) Widths(base int, starts, ends []int, delims []string, right func(i int) bool) []int {
+	width := make([]int, len(starts))
+	for i := range width {
+		width[i] = ends[i] - starts[i]
+		switch {
+		case right(i):
+			prev := base
+			if i > 0 {
+				prev = ends[i-1] + m.gap(i, delims)
+			}
+			if starts[i] > prev {
+				width[i] += starts[i] - prev
+			}
+		case i+1 < len(starts) && !right(i+1):
+			if next := starts[i+1] - m.gap(i+1, delims); next > ends[i] {
+				width[i] += next - ends[i]
+			}
+		}
+	}
+	return width
+}
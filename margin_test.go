@@ -1,6 +1,7 @@
 package main
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -125,3 +126,294 @@ func TestMarginJoin(t *testing.T) {
 		}
 	}
 }
+
+var marginJoinFillTests = []struct {
+	left  int
+	right int
+	fill  string
+	src   []string
+	dst   string
+}{
+	{1, 1, ".", []string{"n", "=", "100"}, "n.=.100"},
+	{2, 1, "-", []string{"n", "=", "100"}, "n--=-100"},
+	{2, 0, "・", []string{"a", "b", "c"}, "a・bc"},
+	{3, 0, "・", []string{"a", "b", "c"}, "a・ bc"},
+	{1, 0, "・", []string{"a", "b", "c"}, "a bc"},
+	{0, 0, ".", []string{"a", "b", "c"}, "abc"},
+}
+
+func TestMarginJoinFill(t *testing.T) {
+	for _, test := range marginJoinFillTests {
+		m := NewMarginWithNumber(test.left, test.right)
+		m.SetFill(test.fill)
+
+		expect := test.dst
+		actual := m.Join(test.src)
+		if actual != expect {
+			t.Errorf("NewMargin(%v, %v, %q).Join(%q) = %q; want %q",
+				test.left, test.right, test.fill, test.src, actual, expect)
+		}
+	}
+}
+
+var marginSetListTests = []struct {
+	format string
+	pairs  []marginPair
+}{
+	{"0:1,1:1,2:1", []marginPair{{0, 1}, {1, 1}, {2, 1}}},
+	{"1,2", []marginPair{{1, 1}, {2, 2}}},
+	{"0:3,4", []marginPair{{0, 3}, {4, 4}}},
+}
+
+func TestMarginSetList(t *testing.T) {
+	for _, test := range marginSetListTests {
+		m, err := NewMargin(test.format)
+		if err != nil {
+			t.Errorf("NewMargin(%q) returns %q; want nil",
+				test.format, err)
+			continue
+		}
+		if !reflect.DeepEqual(m.pairs, test.pairs) {
+			t.Errorf("NewMargin(%q).pairs got %v; want %v",
+				test.format, m.pairs, test.pairs)
+		}
+	}
+}
+
+var marginSetKeyedTests = []struct {
+	format string
+	keyed  map[string]marginPair
+}{
+	{",=0:1", map[string]marginPair{",": {0, 1}}},
+	{",=0:1,==1:1,#=2:1",
+		map[string]marginPair{",": {0, 1}, "=": {1, 1}, "#": {2, 1}}},
+	{":==1:1,=>=2", map[string]marginPair{":=": {1, 1}, "=>": {2, 2}}},
+	{"a=3", map[string]marginPair{"a": {3, 3}}},
+}
+
+func TestMarginSetKeyed(t *testing.T) {
+	for _, test := range marginSetKeyedTests {
+		m, err := NewMargin(test.format)
+		if err != nil {
+			t.Errorf("NewMargin(%q) returns %q; want nil",
+				test.format, err)
+			continue
+		}
+		if !reflect.DeepEqual(m.keyed, test.keyed) {
+			t.Errorf("NewMargin(%q).keyed got %v; want %v",
+				test.format, m.keyed, test.keyed)
+		}
+	}
+}
+
+var marginSetListErrTests = []string{
+	"1,",
+	",1",
+	"1,,2",
+	"1:1,a",
+	"=1",
+	",=",
+	",=1:",
+	",=1,",
+	",=1,==",
+}
+
+func TestMarginSetListErr(t *testing.T) {
+	for _, format := range marginSetListErrTests {
+		_, err := NewMargin(format)
+		if err == nil {
+			t.Errorf("NewMargin(%q) returns nil; want err",
+				format)
+		}
+	}
+}
+
+var marginJoinWithTests = []struct {
+	format string
+	src    []string
+	delims []string
+	dst    string
+}{
+	{"0:1,1:1,2:1", []string{"a", ",", "b", "=", "c", "#", "d"}, nil,
+		"a, b = c  # d"},
+	{"0:1,2", []string{"a", ",", "b", ",", "c", ",", "d"}, nil,
+		"a, b  ,  c  ,  d"},
+	{"0:1,1:0", []string{"1", "2", "3", "4"}, nil,
+		"12 3 4"},
+
+	{",=0:1,==1:1,#=2:1", []string{"a", ",", "b", "=", "c", "#", "d"}, nil,
+		"a, b = c  # d"},
+	{",=0:1", []string{"a", "=", "b", ",", "c"}, nil,
+		"a = b, c"},
+	{",=0:1", []string{"a ", ",  ", "b"}, []string{"a", ",", "b"},
+		"a ,   b"},
+	{",=0:1", []string{"a ", ",  ", "b"}, nil,
+		"a ,   b"},
+}
+
+func TestMarginJoinWith(t *testing.T) {
+	for _, test := range marginJoinWithTests {
+		m, err := NewMargin(test.format)
+		if err != nil {
+			t.Errorf("NewMargin(%q) returns %q; want nil",
+				test.format, err)
+			continue
+		}
+
+		expect := test.dst
+		actual := m.JoinWith(test.src, test.delims)
+		if actual != expect {
+			t.Errorf("NewMargin(%q).JoinWith(%q, %q) = %q; want %q",
+				test.format, test.src, test.delims, actual, expect)
+		}
+	}
+}
+
+var marginOffsetsTests = []struct {
+	margin  string
+	base    int
+	src     []string
+	delims  []string
+	columns []int
+	dst     []int
+}{
+	{"", 0, []string{}, nil, nil, []int{}},
+	{"", 0, []string{"aaa"}, nil, nil, []int{0}},
+	{"", 2, []string{"aaa", "b", "cc", "d", "e"}, nil, nil, []int{2, 6, 8, 11, 13}},
+	{"0:2", 0, []string{"a", "b", "c"}, nil, nil, []int{0, 1, 4}},
+	{"1,2:0", 0, []string{"a", "b", "c", "d", "e"}, nil, nil, []int{0, 2, 4, 7, 8}},
+	{"#=0:2", 0, []string{"a", "#", "b", "=", "c"}, []string{"a", "#", "b", "=", "c"}, nil, []int{0, 1, 4, 6, 8}},
+	{"", 0, []string{"a  ", "=", "b"}, nil, []int{6}, []int{0, 5, 7}},
+	{"", 2, []string{"a", "=", "b"}, nil, []int{6}, []int{2, 5, 7}},
+}
+
+func TestMarginOffsets(t *testing.T) {
+	for _, test := range marginOffsetsTests {
+		m, err := NewMargin(test.margin)
+		if err != nil {
+			t.Errorf("NewMargin(%q) returns %q; want nil",
+				test.margin, err)
+			continue
+		}
+		expect := test.dst
+		actual := m.Offsets(test.base, test.src, test.delims, test.columns)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("%q: Offsets(%d, %q, %q, %v) = %v; want %v",
+				test.margin, test.base, test.src, test.delims, test.columns, actual, expect)
+		}
+	}
+}
+
+var marginWidthsTests = []struct {
+	margin string
+	base   int
+	starts []int
+	ends   []int
+	delims []string
+	right  []bool
+	dst    []int
+}{
+	{"", 0, nil, nil, nil, nil, []int{}},
+	{"", 0, []int{0}, []int{3}, nil, nil, []int{3}},
+	{"", 2, []int{2, 6, 8, 11, 13}, []int{5, 7, 10, 12, 14}, nil, nil, []int{3, 1, 2, 1, 1}},
+	{"0:2", 0, []int{0, 1, 4}, []int{1, 2, 5}, nil, nil, []int{1, 1, 1}},
+	{"#=0:2", 0, []int{0, 1, 4, 6, 8}, []int{1, 2, 5, 7, 9}, []string{"a", "#", "b", "=", "c"}, nil, []int{1, 1, 1, 1, 1}},
+	{"", 0, []int{0, 2, 8}, []int{1, 3, 9}, nil, []bool{true, true, true}, []int{1, 1, 5}},
+	{"", 0, []int{0, 6, 8}, []int{3, 7, 10}, nil, []bool{false, false, true}, []int{5, 1, 2}},
+	{"", 1, []int{3, 5}, []int{4, 6}, nil, []bool{true, false}, []int{3, 1}},
+}
+
+func TestMarginWidths(t *testing.T) {
+	for _, test := range marginWidthsTests {
+		m, err := NewMargin(test.margin)
+		if err != nil {
+			t.Errorf("NewMargin(%q) returns %q; want nil",
+				test.margin, err)
+			continue
+		}
+		expect := test.dst
+		actual := m.Widths(test.base, test.starts, test.ends, test.delims, func(i int) bool {
+			return i < len(test.right) && test.right[i]
+		})
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("%q: Widths(%d, %v, %v, %q) = %v; want %v",
+				test.margin, test.base, test.starts, test.ends, test.delims, actual, expect)
+		}
+	}
+}
+
+var parseColumnsTests = []struct {
+	src string
+	dst []int
+}{
+	{"", nil},
+	{"40", []int{40}},
+	{"40,60", []int{40, 60}},
+	{"1,1", []int{1, 1}},
+}
+
+func TestParseColumns(t *testing.T) {
+	for _, test := range parseColumnsTests {
+		expect := test.dst
+		actual, err := ParseColumns(test.src)
+		if err != nil {
+			t.Errorf("ParseColumns(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("ParseColumns(%q) = %v; want %v",
+				test.src, actual, expect)
+		}
+	}
+}
+
+func TestParseColumnsErr(t *testing.T) {
+	for _, src := range []string{"0", "40,", "a", "-1", "40:60"} {
+		if _, err := ParseColumns(src); err == nil {
+			t.Errorf("ParseColumns(%q) returns nil; want err",
+				src)
+		}
+	}
+}
+
+var marginJoinAtTests = []struct {
+	margin    string
+	base      int
+	delimited bool
+	columns   []int
+	src       []string
+	dst       string
+}{
+	{"", 0, true, []int{5}, []string{"a", "=", "b"}, "a   = b"},
+	{"", 2, true, []int{5}, []string{"a", "=", "b"}, "a = b"},
+	{"", 0, true, []int{5}, []string{"abcdef", "=", "b"}, "abcdef = b"},
+	{"2", 0, true, []int{5}, []string{"abcd", "=", "b"}, "abcd  =  b"},
+	{"", 0, true, []int{5}, []string{"a  ", "=", "b"}, "a   = b"},
+	{"", 0, true, []int{5, 10}, []string{"a", "=", "b", "#", "c"}, "a   = b  # c"},
+	{"", 0, true, []int{5}, []string{"a", "=", "b", "#", "c"}, "a   = b # c"},
+	{"", 0, true, []int{3}, []string{"日本", "=", "b"}, "日本 = b"},
+	{"", 0, true, []int{6}, []string{"日本", "="}, "日本 ="},
+	{"", 0, true, []int{5}, []string{"a"}, "a"},
+	{"", 0, true, []int{5}, []string{}, ""},
+	{"", 0, false, []int{5, 10, 15}, []string{"a", "b", "c", "d"}, "a   b    c    d"},
+	{"", 0, false, []int{3}, []string{"abc", "d", "e"}, "abc d e"},
+}
+
+func TestMarginJoinAt(t *testing.T) {
+	for _, test := range marginJoinAtTests {
+		m, err := NewMargin(test.margin)
+		if err != nil {
+			t.Errorf("NewMargin(%q) returns %q; want nil",
+				test.margin, err)
+			continue
+		}
+		m.delimited = test.delimited
+		expect := test.dst
+		actual := m.JoinAt(test.base, test.src, test.src, test.columns)
+		if actual != expect {
+			t.Errorf("%q: JoinAt(%d, %q, %v) = %q; want %q",
+				test.margin, test.base, test.src, test.columns, actual, expect)
+		}
+	}
+}
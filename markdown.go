@@ -0,0 +1,181 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownSeparatorCell = regexp.MustCompile(`^:?-+:?$`)
+	markdownFence         = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+func SplitMarkdownRow(s string) []string {
+	s = strings.TrimSpace(s)
+	var a []string
+	beg := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			n := 1
+			for i+n < len(s) && s[i+n] == '`' {
+				n++
+			}
+			fence := strings.Repeat("`", n)
+			if j := strings.Index(s[i+n:], fence); j != -1 {
+				i += n + j + n - 1
+			} else {
+				i += n - 1
+			}
+		case '|':
+			a = append(a, s[beg:i])
+			beg = i + 1
+		}
+	}
+	a = append(a, s[beg:])
+
+	if len(a) > 1 && strings.HasPrefix(s, "|") {
+		a = a[1:]
+	}
+	if len(a) > 1 && a[len(a)-1] == "" {
+		a = a[:len(a)-1]
+	}
+	for i := range a {
+		a[i] = strings.TrimSpace(a[i])
+	}
+	return a
+}
+
+func isMarkdownRow(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "|") || len(SplitMarkdownRow(s)) > 1
+}
+
+func ParseMarkdownSeparator(s string) ([]Justify, bool) {
+	if !strings.Contains(s, "-") {
+		return nil, false
+	}
+	a := SplitMarkdownRow(s)
+	js := make([]Justify, len(a))
+	for i, cell := range a {
+		if !markdownSeparatorCell.MatchString(cell) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			js[i] = JustCenter
+		case strings.HasSuffix(cell, ":"):
+			js[i] = JustRight
+		case strings.HasPrefix(cell, ":"):
+			js[i] = JustLeft
+		default:
+			js[i] = JustAuto
+		}
+	}
+	return js, true
+}
+
+func markdownSeparatorCellOf(j Justify, width int) string {
+	switch j {
+	case JustLeft:
+		return ":" + strings.Repeat("-", width-1)
+	case JustRight:
+		return strings.Repeat("-", width-1) + ":"
+	case JustCenter:
+		return ":" + strings.Repeat("-", width-2) + ":"
+	}
+	return strings.Repeat("-", width)
+}
+
+type MarkdownTable struct {
+	indent   string
+	justfies []Justify
+	rows     [][]string
+}
+
+func (t *MarkdownTable) Format(m *Measure) []string {
+	n := len(t.justfies)
+	cols := n
+	for _, row := range t.rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	width := make([]int, cols)
+	for i := range width {
+		width[i] = 3
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if w := m.StringWidth(cell); w > width[i] {
+				width[i] = w
+			}
+		}
+	}
+	format := func(cells []string) string {
+		a := make([]string, n)
+		if len(cells) > n {
+			a = make([]string, len(cells))
+		}
+		for i := range a {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			j := JustLeft
+			if i < n && t.justfies[i] != JustAuto {
+				j = t.justfies[i]
+			}
+			a[i] = j.JustWith(m, " ", width[i], cell)
+		}
+		return t.indent + "| " + strings.Join(a, " | ") + " |"
+	}
+
+	lines := make([]string, 0, len(t.rows)+1)
+	lines = append(lines, format(t.rows[0]))
+	sep := make([]string, n)
+	for i := range sep {
+		sep[i] = markdownSeparatorCellOf(t.justfies[i], width[i])
+	}
+	lines = append(lines, t.indent+"| "+strings.Join(sep, " | ")+" |")
+	for _, row := range t.rows[1:] {
+		lines = append(lines, format(row))
+	}
+	return lines
+}
+
+func FormatMarkdown(lines []string, m *Measure) []string {
+	out := make([]string, 0, len(lines))
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		s := lines[i]
+		if markdownFence.MatchString(s) {
+			inFence = !inFence
+		}
+		if inFence || !isMarkdownRow(s) || i+1 >= len(lines) {
+			out = append(out, s)
+			continue
+		}
+		header := SplitMarkdownRow(s)
+		js, ok := ParseMarkdownSeparator(lines[i+1])
+		if !ok || len(js) != len(header) {
+			out = append(out, s)
+			continue
+		}
+
+		t := &MarkdownTable{
+			indent:   s[:len(s)-len(strings.TrimLeft(s, " \t"))],
+			justfies: js,
+			rows:     [][]string{header},
+		}
+		i += 2
+		for ; i < len(lines) && isMarkdownRow(lines[i]); i++ {
+			t.rows = append(t.rows, SplitMarkdownRow(lines[i]))
+		}
+		i--
+		out = append(out, t.Format(m)...)
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var splitMarkdownRowTests = []struct {
+	src string
+	dst []string
+}{
+	{"a|b", []string{"a", "b"}},
+	{"| a | b |", []string{"a", "b"}},
+	{"  | a | b |  ", []string{"a", "b"}},
+	{"| a | b", []string{"a", "b"}},
+	{"a | b |", []string{"a", "b"}},
+	{"| a |", []string{"a"}},
+	{"| a | |", []string{"a", ""}},
+	{"| a \\| b | c |", []string{"a \\| b", "c"}},
+	{"| `a|b` | c |", []string{"`a|b`", "c"}},
+	{"| ``a`|`b`` | c |", []string{"``a`|`b``", "c"}},
+	{"| `a | b |", []string{"`a", "b"}},
+	{"a \\|", []string{"a \\|"}},
+}
+
+func TestSplitMarkdownRow(t *testing.T) {
+	for _, test := range splitMarkdownRowTests {
+		expect := test.dst
+		actual := SplitMarkdownRow(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("SplitMarkdownRow(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var parseMarkdownSeparatorTests = []struct {
+	src      string
+	justfies []Justify
+	ok       bool
+}{
+	{"|---|---|", []Justify{JustAuto, JustAuto}, true},
+	{"| :-- | --: | :-: |", []Justify{JustLeft, JustRight, JustCenter}, true},
+	{"-|-", []Justify{JustAuto, JustAuto}, true},
+	{"| --- |", []Justify{JustAuto}, true},
+	{"| a | b |", nil, false},
+	{"| --- | b |", nil, false},
+	{"| :: | --- |", nil, false},
+	{"|   |", nil, false},
+}
+
+func TestParseMarkdownSeparator(t *testing.T) {
+	for _, test := range parseMarkdownSeparatorTests {
+		js, ok := ParseMarkdownSeparator(test.src)
+		if ok != test.ok || !reflect.DeepEqual(js, test.justfies) {
+			t.Errorf("ParseMarkdownSeparator(%q) = %v, %v; want %v, %v",
+				test.src, js, ok, test.justfies, test.ok)
+		}
+	}
+}
+
+var formatMarkdownTests = []struct {
+	src string
+	dst string
+}{
+	{`
+| Name | Qty |
+|:-----|----:|
+| apple | 3 |
+| banana | 120 |
+`[1:], `
+| Name   | Qty |
+| :----- | --: |
+| apple  |   3 |
+| banana | 120 |
+`[1:]},
+
+	{`
+text | with pipe
+
+a|b
+-|:-:
+日本語|x
+|c|
+`[1:], `
+text | with pipe
+
+| a      |  b  |
+| ------ | :-: |
+| 日本語 |  x  |
+| c      |     |
+`[1:]},
+
+	{"" +
+		"  | a | b |\n" +
+		"  |---|---|\n" +
+		"  | `x|y` | z \\| w |\n" +
+		"not a row\n", "" +
+		"  | a     | b      |\n" +
+		"  | ----- | ------ |\n" +
+		"  | `x|y` | z \\| w |\n" +
+		"not a row\n"},
+
+	{"```\n| a | b |\n|---|---|\n```\n", "```\n| a | b |\n|---|---|\n```\n"},
+
+	{`
+| a | b |
+|---|--:|
+| c | d | extra |
+| e |
+`[1:], `
+| a   |   b |
+| --- | --: |
+| c   |   d | extra |
+| e   |     |
+`[1:]},
+
+	{`
+| a | b |
+| c | d |
+`[1:], `
+| a | b |
+| c | d |
+`[1:]},
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	for _, test := range formatMarkdownTests {
+		lines := strings.Split(strings.TrimSuffix(test.src, "\n"), "\n")
+
+		expect := test.dst
+		actual := strings.Join(FormatMarkdown(lines, DefaultMeasure), "\n") + "\n"
+		if actual != expect {
+			t.Errorf("FormatMarkdown(%q) got:\n%swant:\n%s",
+				test.src, actual, expect)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Output int
+
+const (
+	OutputText Output = iota
+	OutputMarkdown
+	OutputOrg
+	OutputRST
+	OutputCSV
+	OutputTSV
+	OutputJSON
+	OutputJSONL
+)
+
+func ParseOutput(s string) (Output, error) {
+	switch s {
+	case "", "text":
+		return OutputText, nil
+	case "markdown":
+		return OutputMarkdown, nil
+	case "org":
+		return OutputOrg, nil
+	case "rst":
+		return OutputRST, nil
+	case "csv":
+		return OutputCSV, nil
+	case "tsv":
+		return OutputTSV, nil
+	case "json":
+		return OutputJSON, nil
+	case "jsonl":
+		return OutputJSONL, nil
+	default:
+		return 0, fmt.Errorf("output: invalid format: %s", s)
+	}
+}
+
+func escapeMarkdownCells(row []string) []string {
+	a := make([]string, len(row))
+	for i, cell := range row {
+		var b strings.Builder
+		for j := 0; j < len(cell); j++ {
+			switch {
+			case cell[j] == '\\' && j+1 < len(cell):
+				b.WriteString(cell[j : j+2])
+				j++
+			case cell[j] == '|':
+				b.WriteString("\\|")
+			default:
+				b.WriteByte(cell[j])
+			}
+		}
+		a[i] = b.String()
+	}
+	return a
+}
+
+func escapeOrgCells(row []string) []string {
+	a := make([]string, len(row))
+	for i, cell := range row {
+		a[i] = strings.Replace(cell, "|", `\vert{}`, -1)
+	}
+	return a
+}
+
+type gridLine struct {
+	left  string
+	fill  string
+	cross string
+	right string
+}
+
+type gridStyle struct {
+	top      *gridLine
+	header   *gridLine
+	row      *gridLine
+	bottom   *gridLine
+	vertical string
+}
+
+var (
+	orgStyle = &gridStyle{
+		header:   &gridLine{"|", "-", "+", "|"},
+		vertical: "|",
+	}
+	rstStyle = &gridStyle{
+		top:      &gridLine{"+", "-", "+", "+"},
+		header:   &gridLine{"+", "=", "+", "+"},
+		row:      &gridLine{"+", "-", "+", "+"},
+		bottom:   &gridLine{"+", "-", "+", "+"},
+		vertical: "|",
+	}
+	asciiStyle = &gridStyle{
+		top:      &gridLine{"+", "-", "+", "+"},
+		header:   &gridLine{"+", "-", "+", "+"},
+		bottom:   &gridLine{"+", "-", "+", "+"},
+		vertical: "|",
+	}
+	unicodeStyle = &gridStyle{
+		top:      &gridLine{"┌", "─", "┬", "┐"},
+		header:   &gridLine{"├", "─", "┼", "┤"},
+		bottom:   &gridLine{"└", "─", "┴", "┘"},
+		vertical: "│",
+	}
+)
+
+func ParseBorder(s string) (*gridStyle, error) {
+	switch s {
+	case "":
+		return nil, nil
+	case "ascii":
+		return asciiStyle, nil
+	case "unicode":
+		return unicodeStyle, nil
+	default:
+		return nil, fmt.Errorf("border: invalid format: %s", s)
+	}
+}
+
+type Table struct {
+	measure  *Measure
+	justfies []Justify
+	rows     [][]string
+	width    []int
+	header   int
+}
+
+func NewTable(m *Measure, rows [][]string) *Table {
+	t := &Table{measure: m, header: 1}
+	for _, row := range rows {
+		if len(row) == 1 && row[0] == "" {
+			continue
+		}
+		t.rows = append(t.rows, row)
+		for i, cell := range row {
+			w := m.StringWidth(cell)
+			switch {
+			case i == len(t.width):
+				t.width = append(t.width, w)
+			case w > t.width[i]:
+				t.width[i] = w
+			}
+		}
+	}
+	return t
+}
+
+func (t *Table) SetJustifies(js []Justify) {
+	t.justfies = make([]Justify, len(t.width))
+	for i := range t.justfies {
+		j := JustLeft
+		if i < len(js) {
+			j = js[i]
+		}
+		if j == JustAuto {
+			j = t.autoJustify(i)
+		}
+		t.justfies[i] = j
+	}
+}
+
+func (t *Table) autoJustify(i int) Justify {
+	if len(t.rows) <= t.header {
+		return JustLeft
+	}
+	for _, row := range t.rows[t.header:] {
+		if i < len(row) && row[i] != "" && !numericCell.MatchString(StripEscape(row[i])) {
+			return JustLeft
+		}
+	}
+	return JustRight
+}
+
+func (t *Table) justify(i int) Justify {
+	if i < len(t.justfies) {
+		return t.justfies[i]
+	}
+	return JustLeft
+}
+
+func (t *Table) cells(row []string) []string {
+	a := make([]string, len(t.width))
+	for i := range a {
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		a[i] = t.justify(i).JustWith(t.measure, " ", t.width[i], cell)
+	}
+	return a
+}
+
+func (t *Table) rule(l *gridLine) string {
+	a := make([]string, len(t.width))
+	for i, w := range t.width {
+		a[i] = strings.Repeat(l.fill, w+2)
+	}
+	return l.left + strings.Join(a, l.cross) + l.right
+}
+
+func (t *Table) SetMinWidth(width []int) {
+	for i := 0; i < len(width) && i < len(t.width); i++ {
+		if width[i] > t.width[i] {
+			t.width[i] = width[i]
+		}
+	}
+}
+
+func (t *Table) RenderGrid(style *gridStyle) []string {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	v := style.vertical
+	var lines []string
+	if style.top != nil {
+		lines = append(lines, t.rule(style.top))
+	}
+	for i, row := range t.rows {
+		lines = append(lines, v+" "+strings.Join(t.cells(row), " "+v+" ")+" "+v)
+		switch {
+		case i == t.header-1 && style.header != nil && len(t.rows) > t.header:
+			lines = append(lines, t.rule(style.header))
+		case i < len(t.rows)-1 && style.row != nil:
+			lines = append(lines, t.rule(style.row))
+		}
+	}
+	if style.bottom != nil {
+		lines = append(lines, t.rule(style.bottom))
+	}
+	return lines
+}
+
+func (t *Table) RenderMarkdown() []string {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	width := make([]int, len(t.width))
+	for i, w := range t.width {
+		if w < 3 {
+			w = 3
+		}
+		width[i] = w
+	}
+	t.width, width = width, t.width
+	defer func() { t.width = width }()
+
+	sep := make([]string, len(t.width))
+	for i, w := range t.width {
+		sep[i] = markdownSeparatorCellOf(t.justify(i), w)
+	}
+	lines := make([]string, 0, len(t.rows)+1)
+	for i, row := range t.rows {
+		lines = append(lines, "| "+strings.Join(t.cells(row), " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "| "+strings.Join(sep, " | ")+" |")
+		}
+	}
+	return lines
+}
+
+func (t *Table) Render(o Output) []string {
+	switch o {
+	case OutputMarkdown:
+		return t.RenderMarkdown()
+	case OutputOrg:
+		return t.RenderGrid(orgStyle)
+	case OutputRST:
+		return t.RenderGrid(rstStyle)
+	}
+	return nil
+}
+
+func WriteRecords(w io.Writer, o Output, records [][]string) error {
+	cw := csv.NewWriter(w)
+	if o == OutputTSV {
+		cw.Comma = '\t'
+	}
+	for _, record := range records {
+		if len(record) == 1 && record[0] == "" {
+			continue
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type RowLayout struct {
+	Text       string   `json:"text"`
+	Indent     string   `json:"indent"`
+	Cells      []string `json:"cells"`
+	Delimiters []string `json:"delimiters"`
+	Widths     []int    `json:"widths"`
+	Offsets    []int    `json:"offsets"`
+}
+
+func WriteLayouts(w io.Writer, o Output, layouts []*RowLayout) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if o == OutputJSONL {
+		for _, l := range layouts {
+			if err := enc.Encode(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if layouts == nil {
+		layouts = []*RowLayout{}
+	}
+	enc.SetIndent("", "  ")
+	return enc.Encode(layouts)
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseOutputTests = []struct {
+	src string
+	dst Output
+}{
+	{"", OutputText},
+	{"text", OutputText},
+	{"markdown", OutputMarkdown},
+	{"org", OutputOrg},
+	{"rst", OutputRST},
+	{"csv", OutputCSV},
+	{"tsv", OutputTSV},
+	{"json", OutputJSON},
+	{"jsonl", OutputJSONL},
+}
+
+func TestParseOutput(t *testing.T) {
+	for _, test := range parseOutputTests {
+		expect := test.dst
+		actual, err := ParseOutput(test.src)
+		if err != nil {
+			t.Errorf("ParseOutput(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		if actual != expect {
+			t.Errorf("ParseOutput(%q) = %v; want %v",
+				test.src, actual, expect)
+		}
+	}
+}
+
+func TestParseOutputError(t *testing.T) {
+	for _, src := range []string{"md", "html", "JSON"} {
+		if _, err := ParseOutput(src); err == nil {
+			t.Errorf("ParseOutput(%q) returns nil; want err",
+				src)
+		}
+	}
+}
+
+var escapeMarkdownCellsTests = []struct {
+	src []string
+	dst []string
+}{
+	{[]string{"a", "b"}, []string{"a", "b"}},
+	{[]string{"a|b"}, []string{"a\\|b"}},
+	{[]string{"a\\|b"}, []string{"a\\|b"}},
+	{[]string{"|"}, []string{"\\|"}},
+	{[]string{"a\\"}, []string{"a\\"}},
+}
+
+func TestEscapeMarkdownCells(t *testing.T) {
+	for _, test := range escapeMarkdownCellsTests {
+		expect := test.dst
+		actual := escapeMarkdownCells(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("escapeMarkdownCells(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var escapeOrgCellsTests = []struct {
+	src []string
+	dst []string
+}{
+	{[]string{"a", "b"}, []string{"a", "b"}},
+	{[]string{"a|b"}, []string{`a\vert{}b`}},
+	{[]string{"||"}, []string{`\vert{}\vert{}`}},
+}
+
+func TestEscapeOrgCells(t *testing.T) {
+	for _, test := range escapeOrgCellsTests {
+		expect := test.dst
+		actual := escapeOrgCells(test.src)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("escapeOrgCells(%q) = %q; want %q",
+				test.src, actual, expect)
+		}
+	}
+}
+
+var tableRenderTests = []struct {
+	output   Output
+	justfies []Justify
+	rows     [][]string
+	dst      []string
+}{
+	{
+		OutputMarkdown,
+		[]Justify{JustLeft, JustRight},
+		[][]string{{"name", "qty"}, {"apple", "3"}, {"banana", "120"}},
+		[]string{
+			"| name   | qty |",
+			"| :----- | --: |",
+			"| apple  |   3 |",
+			"| banana | 120 |",
+		},
+	},
+	{
+		OutputMarkdown,
+		[]Justify{JustCenter},
+		[][]string{{"a", "b"}, {"c"}},
+		[]string{
+			"|  a  | b   |",
+			"| :-: | :-- |",
+			"|  c  |     |",
+		},
+	},
+	{
+		OutputOrg,
+		[]Justify{JustLeft, JustAuto},
+		[][]string{{"name", "qty"}, {"apple", "3"}, {"banana", "120"}},
+		[]string{
+			"| name   | qty |",
+			"|--------+-----|",
+			"| apple  |   3 |",
+			"| banana | 120 |",
+		},
+	},
+	{
+		OutputRST,
+		[]Justify{JustAuto, JustAuto},
+		[][]string{{"name", "qty"}, {""}, {"apple", "3"}, {"banana", "x"}},
+		[]string{
+			"+--------+-----+",
+			"| name   | qty |",
+			"+========+=====+",
+			"| apple  | 3   |",
+			"+--------+-----+",
+			"| banana | x   |",
+			"+--------+-----+",
+		},
+	},
+	{
+		OutputRST,
+		[]Justify{JustLeft},
+		[][]string{{"a", "b"}},
+		[]string{
+			"+---+---+",
+			"| a | b |",
+			"+---+---+",
+		},
+	},
+	{
+		OutputOrg,
+		nil,
+		[][]string{{""}},
+		nil,
+	},
+}
+
+func TestTableRender(t *testing.T) {
+	for _, test := range tableRenderTests {
+		tb := NewTable(DefaultMeasure, test.rows)
+		tb.SetJustifies(test.justfies)
+		expect := test.dst
+		actual := tb.Render(test.output)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("Render(%v) of %q:\ngot:\n%s\nwant:\n%s",
+				test.output, test.rows,
+				strings.Join(actual, "\n"), strings.Join(expect, "\n"))
+		}
+	}
+}
+
+func TestParseBorder(t *testing.T) {
+	for src, expect := range map[string]*gridStyle{
+		"":        nil,
+		"ascii":   asciiStyle,
+		"unicode": unicodeStyle,
+	} {
+		actual, err := ParseBorder(src)
+		if err != nil {
+			t.Errorf("ParseBorder(%q) returns %q; want nil",
+				src, err)
+			continue
+		}
+		if actual != expect {
+			t.Errorf("ParseBorder(%q) = %v; want %v",
+				src, actual, expect)
+		}
+	}
+	for _, src := range []string{"box", "Unicode"} {
+		if _, err := ParseBorder(src); err == nil {
+			t.Errorf("ParseBorder(%q) returns nil; want err",
+				src)
+		}
+	}
+}
+
+var tableRenderGridTests = []struct {
+	header int
+	width  []int
+	rows   [][]string
+	dst    []string
+}{
+	{
+		0,
+		nil,
+		[][]string{{"a", "bb"}, {"ccc", "d"}},
+		[]string{
+			"┌─────┬────┐",
+			"│ a   │ bb │",
+			"│ ccc │ d  │",
+			"└─────┴────┘",
+		},
+	},
+	{
+		1,
+		[]int{4},
+		[][]string{{"a", "bb"}, {"ccc", "d"}},
+		[]string{
+			"┌──────┬────┐",
+			"│ a    │ bb │",
+			"├──────┼────┤",
+			"│ ccc  │ d  │",
+			"└──────┴────┘",
+		},
+	},
+	{
+		2,
+		nil,
+		[][]string{{"ab", "c"}, {"d", ""}, {"e", "f"}},
+		[]string{
+			"┌────┬───┐",
+			"│ ab │ c │",
+			"│ d  │   │",
+			"├────┼───┤",
+			"│ e  │ f │",
+			"└────┴───┘",
+		},
+	},
+	{
+		1,
+		nil,
+		[][]string{{"日本", "語"}},
+		[]string{
+			"┌──────┬────┐",
+			"│ 日本 │ 語 │",
+			"└──────┴────┘",
+		},
+	},
+}
+
+func TestTableRenderGrid(t *testing.T) {
+	for _, test := range tableRenderGridTests {
+		tb := NewTable(DefaultMeasure, test.rows)
+		tb.header = test.header
+		tb.SetMinWidth(test.width)
+		tb.SetJustifies(nil)
+		expect := test.dst
+		actual := tb.RenderGrid(unicodeStyle)
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("RenderGrid of %q:\ngot:\n%s\nwant:\n%s",
+				test.rows,
+				strings.Join(actual, "\n"), strings.Join(expect, "\n"))
+		}
+	}
+}
+
+func TestTableRenderGridRST(t *testing.T) {
+	tb := NewTable(DefaultMeasure, [][]string{{"a", "bb"}, {"ccc", "d"}, {"e", "f"}})
+	tb.SetJustifies(nil)
+	expect := []string{
+		"+-----+----+",
+		"| a   | bb |",
+		"+=====+====+",
+		"| ccc | d  |",
+		"+-----+----+",
+		"| e   | f  |",
+		"+-----+----+",
+	}
+	actual := tb.RenderGrid(rstStyle)
+	if !reflect.DeepEqual(actual, expect) {
+		t.Errorf("RenderGrid(rstStyle):\ngot:\n%s\nwant:\n%s",
+			strings.Join(actual, "\n"), strings.Join(expect, "\n"))
+	}
+}
+
+var writeRecordsTests = []struct {
+	output  Output
+	records [][]string
+	dst     string
+}{
+	{OutputCSV, [][]string{{"a", "b"}, {"c", "d"}}, "a,b\nc,d\n"},
+	{OutputCSV, [][]string{{"a,b", "c"}}, "\"a,b\",c\n"},
+	{OutputCSV, [][]string{{"say \"hi\"", "x"}}, "\"say \"\"hi\"\"\",x\n"},
+	{OutputCSV, [][]string{{"a"}, {""}, {"b"}}, "a\nb\n"},
+	{OutputTSV, [][]string{{"a,b", "c"}}, "a,b\tc\n"},
+	{OutputTSV, [][]string{{"a\tb", "c"}}, "\"a\tb\"\tc\n"},
+}
+
+func TestWriteRecords(t *testing.T) {
+	for _, test := range writeRecordsTests {
+		var b bytes.Buffer
+		if err := WriteRecords(&b, test.output, test.records); err != nil {
+			t.Errorf("WriteRecords(%v, %q) returns %q; want nil",
+				test.output, test.records, err)
+			continue
+		}
+		expect := test.dst
+		actual := b.String()
+		if actual != expect {
+			t.Errorf("WriteRecords(%v, %q) = %q; want %q",
+				test.output, test.records, actual, expect)
+		}
+	}
+}
+
+var writeLayoutsTests = []struct {
+	output  Output
+	layouts []*RowLayout
+	dst     string
+}{
+	{OutputJSON, nil, "[]\n"},
+	{OutputJSONL, nil, ""},
+	{
+		OutputJSONL,
+		[]*RowLayout{
+			{"a<b", "", []string{"a<b"}, []string{}, []int{3}, []int{0}},
+			{" x = 1", " ", []string{"x", "1"}, []string{"="}, []int{1, 1, 1}, []int{1, 3, 5}},
+		},
+		`{"text":"a<b","indent":"","cells":["a<b"],"delimiters":[],"widths":[3],"offsets":[0]}
+{"text":" x = 1","indent":" ","cells":["x","1"],"delimiters":["="],"widths":[1,1,1],"offsets":[1,3,5]}
+`,
+	},
+	{
+		OutputJSON,
+		[]*RowLayout{
+			{"a", "", []string{"a"}, []string{}, []int{1}, []int{0}},
+		},
+		`[
+  {
+    "text": "a",
+    "indent": "",
+    "cells": [
+      "a"
+    ],
+    "delimiters": [],
+    "widths": [
+      1
+    ],
+    "offsets": [
+      0
+    ]
+  }
+]
+`,
+	},
+}
+
+func TestWriteLayouts(t *testing.T) {
+	for _, test := range writeLayoutsTests {
+		var b bytes.Buffer
+		if err := WriteLayouts(&b, test.output, test.layouts); err != nil {
+			t.Errorf("WriteLayouts(%v) returns %q; want nil",
+				test.output, err)
+			continue
+		}
+		expect := test.dst
+		actual := b.String()
+		if actual != expect {
+			t.Errorf("WriteLayouts(%v) = %q; want %q",
+				test.output, actual, expect)
+		}
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-
-	"github.com/mattn/go-runewidth"
 )
 
 type Justify int
@@ -14,9 +12,14 @@ const (
 	JustLeft Justify = iota
 	JustCenter
 	JustRight
+	JustAuto
 )
 
-var justfiesSequence = regexp.MustCompile("^[lcr]+$")
+var (
+	justfiesSequence  = regexp.MustCompile("^[lcra]+$")
+	justifyRuleFormat = regexp.MustCompile(`^([td]?-?\d+):([lcra])$`)
+	numericCell       = regexp.MustCompile(`^[-+]?(\d[\d,]*(\.\d*)?|\.\d+)([eE][-+]?\d+)?%?$`)
+)
 
 func ParseJustifies(seq string) ([]Justify, error) {
 	switch {
@@ -32,6 +35,8 @@ func ParseJustifies(seq string) ([]Justify, error) {
 				js = append(js, JustCenter)
 			case 'r':
 				js = append(js, JustRight)
+			case 'a':
+				js = append(js, JustAuto)
 			}
 		}
 		return js, nil
@@ -41,49 +46,201 @@ func ParseJustifies(seq string) ([]Justify, error) {
 }
 
 func (j Justify) Just(width int, s string) string {
-	w := runewidth.StringWidth(s)
+	return j.JustWith(DefaultMeasure, " ", width, s)
+}
+
+func (j Justify) JustWith(m *Measure, fill string, width int, s string) string {
+	return j.justWith(m, fill, fill, width, s)
+}
+
+func (j Justify) justWith(m *Measure, lead, trail string, width int, s string) string {
+	w := m.StringWidth(s)
 	if width <= w {
 		return s
 	}
 	switch j {
 	case JustLeft:
-		return s + strings.Repeat(" ", width-w)
+		return s + m.Fill(trail, width-w)
 	case JustRight:
-		return strings.Repeat(" ", width-w) + s
+		return m.Fill(lead, width-w) + s
 	case JustCenter:
 		n := width - w
 		l, r := n/2, n/2
 		if n%2 != 0 {
 			r += 1
 		}
-		return strings.Repeat(" ", l) + s + strings.Repeat(" ", r)
+		return m.Fill(lead, l) + s + m.Fill(trail, r)
 	}
-	return s + strings.Repeat(" ", width-w)
+	return s + m.Fill(trail, width-w)
 }
 
-type Padding struct {
-	justfies []Justify
-	width    []int
+type JustifyRule struct {
+	cell    CellIndex
+	justify Justify
 }
 
-func NewPadding(seq string) (p *Padding, err error) {
-	p = &Padding{}
-	p.justfies, err = ParseJustifies(seq)
+func ParseJustifyRule(format string) (JustifyRule, error) {
+	a := justifyRuleFormat.FindStringSubmatch(format)
+	if a == nil {
+		return JustifyRule{}, fmt.Errorf("padding: invalid format: %s", format)
+	}
+	c, err := ParseCellIndex(a[1])
+	if err != nil {
+		return JustifyRule{}, err
+	}
+	js, err := ParseJustifies(a[2])
 	if err != nil {
-		return nil, err
+		return JustifyRule{}, err
+	}
+	return JustifyRule{cell: c, justify: js[0]}, nil
+}
+
+func (r JustifyRule) Match(i, n int, delimited bool) bool {
+	return r.cell.Match(i, n, delimited)
+}
+
+func ParseWidths(format string) ([]int, error) {
+	if format == "" {
+		return nil, nil
+	}
+	width, ok := parseNumbers(format)
+	if !ok {
+		return nil, fmt.Errorf("padding: invalid widths: %s", format)
+	}
+	return width, nil
+}
+
+type Padding struct {
+	justfies      []Justify
+	delimJustfies []Justify
+	rules         []JustifyRule
+	delimited     bool
+	measure       *Measure
+	fill          string
+	fillCells     []CellIndex
+	minWidth      int
+	baseWidth     []int
+	width         []int
+	textual       []bool
+}
+
+func NewPadding(format string) (p *Padding, err error) {
+	p = &Padding{measure: DefaultMeasure, fill: " "}
+	items := strings.Split(format, ",")
+	if !justifyRuleFormat.MatchString(items[0]) {
+		p.justfies, err = ParseJustifies(items[0])
+		if err != nil {
+			return nil, err
+		}
+		items = items[1:]
+	} else {
+		p.justfies = []Justify{JustLeft}
+	}
+	for _, item := range items {
+		r, err := ParseJustifyRule(item)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, r)
 	}
 	return p, nil
 }
 
+func (p *Padding) SetDelimiterJustifies(seq string) (err error) {
+	if seq == "" {
+		p.delimJustfies = nil
+		return nil
+	}
+	p.delimJustfies, err = ParseJustifies(seq)
+	return err
+}
+
+func (p *Padding) SetFill(fill string, cells []CellIndex) {
+	p.fill = fill
+	p.fillCells = cells
+}
+
+func (p *Padding) fillAt(i, n int) string {
+	if len(p.fillCells) == 0 {
+		return p.fill
+	}
+	for _, c := range p.fillCells {
+		if c.Match(i, n, p.delimited) {
+			return p.fill
+		}
+	}
+	return " "
+}
+
+func (p *Padding) SetWidth(minWidth int, width []int) {
+	p.minWidth = minWidth
+	p.width = make([]int, len(width))
+	copy(p.width, width)
+	p.baseWidth = make([]int, len(p.width))
+	copy(p.baseWidth, p.width)
+}
+
+func (p *Padding) Fork() *Padding {
+	q := *p
+	q.width = make([]int, len(p.baseWidth))
+	copy(q.width, p.baseWidth)
+	q.textual = nil
+	return &q
+}
+
+func (p *Padding) Width() []int {
+	width := make([]int, len(p.width))
+	copy(width, p.width)
+	return width
+}
+
 func (p *Padding) UpdateWidth(a []string) {
 	for i, s := range a {
-		w := runewidth.StringWidth(s)
+		w := p.measure.StringWidth(s)
+		if w < p.minWidth && !(p.delimited && i%2 != 0) {
+			w = p.minWidth
+		}
 		switch {
 		case i == len(p.width):
 			p.width = append(p.width, w)
 		case w > p.width[i]:
 			p.width[i] = w
 		}
+
+		if i == len(p.textual) {
+			p.textual = append(p.textual, false)
+		}
+		if s != "" && !numericCell.MatchString(StripEscape(s)) {
+			p.textual[i] = true
+		}
+	}
+}
+
+func (p *Padding) Grow(width []int) {
+	for i, w := range width {
+		switch {
+		case i == len(p.width):
+			p.width = append(p.width, w)
+		case w > p.width[i]:
+			p.width[i] = w
+		}
+	}
+}
+
+func (p *Padding) Round(n int, base int, gap func(i int) int) {
+	if n < 2 {
+		return
+	}
+	offset := base
+	for i := 0; i+1 < len(p.width); i++ {
+		offset += p.width[i] + gap(i+1)
+		if p.delimited && (i+1)%2 != 0 {
+			continue
+		}
+		if r := offset % n; r != 0 {
+			p.width[i] += n - r
+			offset += n - r
+		}
 	}
 }
 
@@ -95,11 +252,38 @@ func (p *Padding) justKind(i int) Justify {
 	return p.justfies[j]
 }
 
-func (p *Padding) Format(a []string) []string {
+func (p *Padding) justKindAt(i, n int) Justify {
+	for k := len(p.rules) - 1; k >= 0; k-- {
+		if p.rules[k].Match(i, n, p.delimited) {
+			return p.rules[k].justify
+		}
+	}
+	if p.delimited && i%2 != 0 && p.delimJustfies != nil {
+		return p.delimJustfies[(i/2)%len(p.delimJustfies)]
+	}
+	return p.justKind(i)
+}
+
+func (p *Padding) resolve(i int, j Justify) Justify {
+	if j != JustAuto {
+		return j
+	}
+	if i < len(p.textual) && !p.textual[i] {
+		return JustRight
+	}
+	return JustLeft
+}
+
+func (p *Padding) Format(a []string, n int) []string {
 	for i := 0; i < len(a) && i < len(p.width); i++ {
-		j := p.justKind(i)
+		j := p.resolve(i, p.justKindAt(i, n))
 		w := p.width[i]
-		a[i] = j.Just(w, a[i])
+		fill := p.fillAt(i, n)
+		if i == len(a)-1 {
+			a[i] = j.justWith(p.measure, fill, " ", w, a[i])
+			continue
+		}
+		a[i] = j.JustWith(p.measure, fill, w, a[i])
 	}
 	return a
 }
@@ -49,6 +49,11 @@ var justifyTests = []struct {
 	{JustLeft, 9, "日本語", "日本語   "},
 	{JustRight, 9, "日本語", "   日本語"},
 	{JustCenter, 9, "日本語", " 日本語  "},
+
+	// escape sequence
+	{JustLeft, 5, "\x1b[31mabc\x1b[0m", "\x1b[31mabc\x1b[0m  "},
+	{JustRight, 5, "\x1b[31mabc\x1b[0m", "  \x1b[31mabc\x1b[0m"},
+	{JustCenter, 5, "\x1b[31mabc\x1b[0m", " \x1b[31mabc\x1b[0m "},
 }
 
 func TestJustfy(t *testing.T) {
@@ -63,6 +68,32 @@ func TestJustfy(t *testing.T) {
 	}
 }
 
+var justifyWithTests = []struct {
+	model   WidthModel
+	justify Justify
+	width   int
+	src     string
+	dst     string
+}{
+	{WidthGrapheme, JustLeft, 4, "\U0001F1EF\U0001F1F5", "\U0001F1EF\U0001F1F5  "},
+	{WidthGrapheme, JustRight, 4, "\U0001F1EF\U0001F1F5", "  \U0001F1EF\U0001F1F5"},
+	{WidthGrapheme, JustRight, 3, "e\u0301", "  e\u0301"},
+	{WidthRune, JustRight, 3, "e\u0301", "  e\u0301"},
+}
+
+func TestJustifyWith(t *testing.T) {
+	for _, test := range justifyWithTests {
+		m := NewMeasure(test.model)
+
+		expect := test.dst
+		actual := test.justify.JustWith(m, " ", test.width, test.src)
+		if actual != expect {
+			t.Errorf("%v.JustWith(%v, %v, %q) = %q; want %q",
+				test.justify, test.model, test.width, test.src, actual, expect)
+		}
+	}
+}
+
 var paddingUpdateWidthTests = []struct {
 	a      []string
 	before []int
@@ -95,6 +126,10 @@ var paddingUpdateWidthTests = []struct {
 		[]int{1, 1}, []int{6, 12}},
 	{[]string{"「おはよう」", "『こんにちは』"},
 		[]int{1, 1}, []int{12, 14}},
+
+	// ignore escape sequence
+	{[]string{"\x1b[31mabc\x1b[0m", "\x1b]8;;http://example.com\x07a\x1b]8;;\x07"},
+		[]int{1, 1}, []int{3, 1}},
 }
 
 func TestPaddingUpdateWidth(t *testing.T) {
@@ -154,6 +189,10 @@ var paddingJustKindTests = []struct {
 	{"lcrr", 5, JustRight},
 	{"lcrr", 6, JustRight},
 	{"lcrr", 7, JustCenter},
+
+	{"a", 0, JustAuto},
+	{"la", 1, JustAuto},
+	{"la", 2, JustAuto},
 }
 
 func TestsPaddingJustKind(t *testing.T) {
@@ -174,6 +213,131 @@ func TestsPaddingJustKind(t *testing.T) {
 	}
 }
 
+var paddingJustKindAtTests = []struct {
+	format    string
+	delimited bool
+	n         int
+	src       int
+	dst       Justify
+}{
+	{"l,5:r", false, 7, 4, JustLeft},
+	{"l,5:r", false, 7, 5, JustRight},
+	{"l,5:r", false, 7, 6, JustLeft},
+	{"5:r", false, 7, 0, JustLeft},
+	{"5:r", false, 7, 5, JustRight},
+
+	{"l,-1:r", false, 4, 3, JustRight},
+	{"l,-1:r", false, 4, 2, JustLeft},
+	{"l,-2:c", false, 4, 2, JustCenter},
+	{"l,-1:r", false, 1, 0, JustRight},
+
+	{"r,1:l,1:c", false, 3, 1, JustCenter},
+	{"rl,0:c", false, 3, 0, JustCenter},
+	{"rl,0:c", false, 3, 1, JustRight},
+	{"rl,0:c", false, 3, 2, JustLeft},
+
+	{"l,t1:r", true, 5, 1, JustLeft},
+	{"l,t1:r", true, 5, 2, JustRight},
+	{"l,t-1:r", true, 5, 4, JustRight},
+	{"l,t1:r", false, 5, 1, JustRight},
+
+	{"l,d0:r", true, 5, 1, JustRight},
+	{"l,d0:r", true, 5, 2, JustLeft},
+	{"l,d-1:c", true, 5, 3, JustCenter},
+	{"l,d-1:c", true, 5, 1, JustLeft},
+	{"l,d0:r", false, 5, 1, JustLeft},
+}
+
+func TestPaddingJustKindAt(t *testing.T) {
+	for _, test := range paddingJustKindAtTests {
+		p, err := NewPadding(test.format)
+		if err != nil {
+			t.Errorf("NewPadding(%q) returns %q, want nil",
+				test.format, err)
+			continue
+		}
+		p.delimited = test.delimited
+
+		expect := test.dst
+		actual := p.justKindAt(test.src, test.n)
+		if actual != expect {
+			t.Errorf("NewPadding(%q).justKindAt(%v, %v) = %v; want %v",
+				test.format, test.src, test.n, actual, expect)
+		}
+	}
+}
+
+var paddingDelimiterJustifyTests = []struct {
+	format    string
+	delimSeq  string
+	delimited bool
+	n         int
+	src       int
+	dst       Justify
+}{
+	{"l", "r", true, 5, 0, JustLeft},
+	{"l", "r", true, 5, 1, JustRight},
+	{"l", "r", true, 5, 2, JustLeft},
+	{"l", "r", true, 5, 3, JustRight},
+	{"r", "l", true, 5, 1, JustLeft},
+	{"r", "l", true, 5, 2, JustRight},
+
+	{"l", "lrc", true, 9, 1, JustLeft},
+	{"l", "lrc", true, 9, 3, JustRight},
+	{"l", "lrc", true, 9, 5, JustCenter},
+	{"l", "lrc", true, 9, 7, JustLeft},
+
+	{"l", "r", false, 5, 1, JustLeft},
+	{"l,d0:c", "r", true, 5, 1, JustCenter},
+	{"l,d0:c", "r", true, 5, 3, JustRight},
+}
+
+func TestPaddingDelimiterJustify(t *testing.T) {
+	for _, test := range paddingDelimiterJustifyTests {
+		p, err := NewPadding(test.format)
+		if err != nil {
+			t.Errorf("NewPadding(%q) returns %q, want nil",
+				test.format, err)
+			continue
+		}
+		if err := p.SetDelimiterJustifies(test.delimSeq); err != nil {
+			t.Errorf("SetDelimiterJustifies(%q) returns %q, want nil",
+				test.delimSeq, err)
+			continue
+		}
+		p.delimited = test.delimited
+
+		expect := test.dst
+		actual := p.justKindAt(test.src, test.n)
+		if actual != expect {
+			t.Errorf("NewPadding(%q, %q).justKindAt(%v, %v) = %v; want %v",
+				test.format, test.delimSeq, test.src, test.n, actual, expect)
+		}
+	}
+}
+
+var newPaddingErrTests = []string{
+	"x",
+	"l,",
+	",l",
+	"l,r",
+	"l,1",
+	"l,1:x",
+	"l,1:rl",
+	"l,x1:r",
+	"l,1:r,",
+}
+
+func TestNewPaddingErr(t *testing.T) {
+	for _, format := range newPaddingErrTests {
+		_, err := NewPadding(format)
+		if err == nil {
+			t.Errorf("NewPadding(%q) returns nil; want err",
+				format)
+		}
+	}
+}
+
 var paddingFormatTests = []struct {
 	seq   string
 	width []int
@@ -232,10 +396,274 @@ func TestPaddingFormat(t *testing.T) {
 		p.width = test.width
 
 		expect := test.dst
-		actual := p.Format(test.src)
+		actual := p.Format(test.src, len(test.src))
 		if !reflect.DeepEqual(actual, expect) {
 			t.Errorf("NewPadding(%q, %v).Format(%q) = %q; want %q",
 				test.seq, test.width, test.src, actual, expect)
 		}
 	}
 }
+
+var paddingFormatAutoTests = []struct {
+	seq  string
+	rows [][]string
+	src  []string
+	dst  []string
+}{
+	{"a", [][]string{{"a", "10"}, {"bbb", "2"}},
+		[]string{"a", "2"},
+		[]string{"a  ", " 2"}},
+	{"a", [][]string{{"1", "x"}, {"100", "yyy"}},
+		[]string{"1", "x"},
+		[]string{"  1", "x  "}},
+	{"a", [][]string{{"-1.5", "1,000"}, {"+20", "3e10"}},
+		[]string{"+20", "1,000"},
+		[]string{" +20", "1,000"}},
+	{"a", [][]string{{"10", "a"}, {"", "bb"}},
+		[]string{"", "a"},
+		[]string{"  ", "a "}},
+	{"a", [][]string{{"10", "a"}, {"1x", "bb"}},
+		[]string{"10", "a"},
+		[]string{"10", "a "}},
+	{"ra", [][]string{{"x", "1"}, {"yyy", "100"}},
+		[]string{"x", "1"},
+		[]string{"  x", "  1"}},
+}
+
+func TestPaddingFormatAuto(t *testing.T) {
+	for _, test := range paddingFormatAutoTests {
+		p, err := NewPadding(test.seq)
+		if err != nil {
+			t.Errorf("NewPadding(%q) returns %q, want nil",
+				test.seq, err)
+			continue
+		}
+		for _, row := range test.rows {
+			p.UpdateWidth(row)
+		}
+
+		expect := test.dst
+		actual := p.Format(test.src, len(test.src))
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("NewPadding(%q).Format(%q) after %q = %q; want %q",
+				test.seq, test.src, test.rows, actual, expect)
+		}
+	}
+}
+
+var parseWidthsTests = []struct {
+	format string
+	width  []int
+}{
+	{"", nil},
+	{"3", []int{3}},
+	{"10,3,20", []int{10, 3, 20}},
+	{"0,03", []int{0, 3}},
+}
+
+func TestParseWidths(t *testing.T) {
+	for _, test := range parseWidthsTests {
+		width, err := ParseWidths(test.format)
+		if err != nil {
+			t.Errorf("ParseWidths(%q) returns %q; want nil",
+				test.format, err)
+			continue
+		}
+		if !reflect.DeepEqual(width, test.width) {
+			t.Errorf("ParseWidths(%q) = %v; want %v",
+				test.format, width, test.width)
+		}
+	}
+}
+
+var parseWidthsErrTests = []string{
+	"a",
+	"-1",
+	"1,",
+	",1",
+	"1,,1",
+	"1:1",
+}
+
+func TestParseWidthsErr(t *testing.T) {
+	for _, format := range parseWidthsErrTests {
+		_, err := ParseWidths(format)
+		if err == nil {
+			t.Errorf("ParseWidths(%q) returns nil; want err",
+				format)
+		}
+	}
+}
+
+var paddingSetWidthTests = []struct {
+	minWidth  int
+	width     []int
+	delimited bool
+	a         []string
+	after     []int
+}{
+	{0, nil, false, []string{"aaa", "a"},
+		[]int{3, 1}},
+	{0, []int{5, 1, 5}, false, []string{"aaa", "a"},
+		[]int{5, 1, 5}},
+	{0, []int{1, 1}, false, []string{"aaa", "a", "aa"},
+		[]int{3, 1, 2}},
+	{2, nil, false, []string{"aaa", "a"},
+		[]int{3, 2}},
+	{2, []int{1, 4}, false, []string{"a", "a", "a"},
+		[]int{2, 4, 2}},
+	{2, nil, true, []string{"a", "=", "a"},
+		[]int{2, 1, 2}},
+	{2, []int{1, 3}, true, []string{"a", "=", "a"},
+		[]int{2, 3, 2}},
+}
+
+func TestPaddingSetWidth(t *testing.T) {
+	for _, test := range paddingSetWidthTests {
+		p, _ := NewPadding("")
+		p.delimited = test.delimited
+		p.SetWidth(test.minWidth, test.width)
+		p.UpdateWidth(test.a)
+
+		expect := test.after
+		actual := p.Width()
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("SetWidth(%v, %v).UpdateWidth(%v) got %v; want %v",
+				test.minWidth, test.width, test.a, actual, expect)
+		}
+	}
+}
+
+var paddingFormatFillTests = []struct {
+	seq   string
+	fill  string
+	cells string
+	width []int
+	src   []string
+	dst   []string
+}{
+	{"", ".", "", []int{4, 1, 4},
+		[]string{"a", "=", "b"},
+		[]string{"a...", "=", "b   "}},
+	{"r", ".", "", []int{4, 1, 4},
+		[]string{"a", "=", "b"},
+		[]string{"...a", "=", "...b"}},
+	{"c", "*", "", []int{5, 1},
+		[]string{"a", "="},
+		[]string{"**a**", "="}},
+	{"", ".", "0", []int{4, 1, 4},
+		[]string{"a", "=", "b"},
+		[]string{"a...", "=", "b   "}},
+	{"r", ".", "-1", []int{4, 1, 4},
+		[]string{"a", "=", "b"},
+		[]string{"   a", "=", "...b"}},
+	{"c", ".", "", []int{4, 1, 4},
+		[]string{"a", "=", "b"},
+		[]string{".a..", "=", ".b  "}},
+}
+
+func TestPaddingFormatFill(t *testing.T) {
+	for _, test := range paddingFormatFillTests {
+		p, err := NewPadding(test.seq)
+		if err != nil {
+			t.Errorf("NewPadding(%q) returns %q, want nil",
+				test.seq, err)
+			continue
+		}
+		cells, err := ParseCellIndexes(test.cells)
+		if err != nil {
+			t.Errorf("ParseCellIndexes(%q) returns %q, want nil",
+				test.cells, err)
+			continue
+		}
+		p.SetFill(test.fill, cells)
+		p.width = test.width
+
+		expect := test.dst
+		actual := p.Format(test.src, len(test.src))
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("NewPadding(%q, %q, %q).Format(%q) = %q; want %q",
+				test.seq, test.fill, test.cells, test.src, actual, expect)
+		}
+	}
+}
+
+var paddingGrowTests = []struct {
+	before []int
+	width  []int
+	after  []int
+}{
+	{[]int{}, []int{3, 1}, []int{3, 1}},
+	{[]int{2, 1, 5}, []int{3, 1}, []int{3, 1, 5}},
+	{[]int{4, 2}, []int{3, 1}, []int{4, 2}},
+	{[]int{4}, []int{0, 1}, []int{4, 1}},
+}
+
+func TestPaddingGrow(t *testing.T) {
+	for _, test := range paddingGrowTests {
+		p, _ := NewPadding("")
+		p.SetWidth(0, test.before)
+		p.Grow(test.width)
+		expect := test.after
+		actual := p.Width()
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("%v: Grow(%v) = %v; want %v",
+				test.before, test.width, actual, expect)
+		}
+	}
+}
+
+var paddingRoundTests = []struct {
+	n         int
+	base      int
+	delimited bool
+	before    []int
+	after     []int
+}{
+	{0, 0, false, []int{1, 2, 3}, []int{1, 2, 3}},
+	{1, 0, false, []int{1, 2, 3}, []int{1, 2, 3}},
+	{4, 0, false, []int{1, 2, 3}, []int{3, 3, 3}},
+	{4, 0, false, []int{3, 7, 3}, []int{3, 7, 3}},
+	{8, 0, false, []int{5, 8}, []int{7, 8}},
+	{4, 2, false, []int{1, 2}, []int{1, 2}},
+	{4, 4, true, []int{5, 1, 3, 1, 5}, []int{5, 1, 3, 3, 5}},
+	{4, 0, true, []int{1, 1, 1}, []int{1, 1, 1}},
+	{4, 0, true, []int{2, 1, 1}, []int{2, 4, 1}},
+}
+
+func TestPaddingRound(t *testing.T) {
+	for _, test := range paddingRoundTests {
+		p, _ := NewPadding("")
+		p.delimited = test.delimited
+		p.SetWidth(0, test.before)
+		p.Round(test.n, test.base, func(i int) int { return 1 })
+		expect := test.after
+		actual := p.Width()
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("%v: Round(%d, %d) = %v; want %v",
+				test.before, test.n, test.base, actual, expect)
+		}
+	}
+}
+
+func TestPaddingFork(t *testing.T) {
+	p, _ := NewPadding("r")
+	p.delimited = true
+	p.SetWidth(2, []int{4})
+	p.UpdateWidth([]string{"abcdef", "=", "x"})
+
+	q := p.Fork()
+	if expect, actual := []int{4}, q.Width(); !reflect.DeepEqual(actual, expect) {
+		t.Errorf("Fork().Width() = %v; want %v", actual, expect)
+	}
+	q.UpdateWidth([]string{"a", "=", "xyz"})
+	if expect, actual := []int{4, 1, 3}, q.Width(); !reflect.DeepEqual(actual, expect) {
+		t.Errorf("Fork().UpdateWidth() = %v; want %v", actual, expect)
+	}
+	if expect, actual := []int{6, 1, 2}, p.Width(); !reflect.DeepEqual(actual, expect) {
+		t.Errorf("Width() after Fork() = %v; want %v", actual, expect)
+	}
+	if !q.delimited || q.justfies[0] != JustRight || q.minWidth != 2 {
+		t.Errorf("Fork() = %+v; want the same settings as %+v", q, p)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+type Section struct {
+	narrowest []int
+	widest    []int
+}
+
+func (s *Section) Fits(width []int, maxPad int) bool {
+	for i, w := range width {
+		if i >= len(s.narrowest) {
+			break
+		}
+		lo, hi := s.narrowest[i], s.widest[i]
+		if w < lo {
+			lo = w
+		}
+		if w > hi {
+			hi = w
+		}
+		if hi-lo > maxPad {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Section) Add(width []int) {
+	for i, w := range width {
+		switch {
+		case i == len(s.narrowest):
+			s.narrowest = append(s.narrowest, w)
+			s.widest = append(s.widest, w)
+		case w < s.narrowest[i]:
+			s.narrowest[i] = w
+		case w > s.widest[i]:
+			s.widest[i] = w
+		}
+	}
+}
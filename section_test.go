@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+)
+
+var sectionFitsTests = []struct {
+	widths [][]int
+	width  []int
+	maxPad int
+	dst    bool
+}{
+	{nil, []int{100}, 0, true},
+	{[][]int{{1}, {2}}, []int{3}, 2, true},
+	{[][]int{{1}, {2}}, []int{4}, 2, false},
+	{[][]int{{5}, {6}}, []int{3}, 2, false},
+	{[][]int{{5}, {6}}, []int{4}, 2, true},
+	{[][]int{{1, 1}}, []int{1, 9}, 2, false},
+	{[][]int{{1}}, []int{1, 9}, 2, true},
+	{[][]int{{1, 9}}, []int{1}, 2, true},
+}
+
+func TestSectionFits(t *testing.T) {
+	for _, test := range sectionFitsTests {
+		s := &Section{}
+		for _, width := range test.widths {
+			s.Add(width)
+		}
+		expect := test.dst
+		actual := s.Fits(test.width, test.maxPad)
+		if actual != expect {
+			t.Errorf("%v: Fits(%v, %d) = %v; want %v",
+				test.widths, test.width, test.maxPad, actual, expect)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -46,9 +48,27 @@ END:
 }
 
 func (s *Space) Trim(t string) string {
-	return strings.TrimSpace(t)
+	return TrimSpace(t)
 }
 
 func (s *Space) Adjust(t string) string {
-	return s.leadingSpace + strings.TrimRightFunc(t, unicode.IsSpace)
+	return s.AdjustWith(s.leadingSpace, t)
+}
+
+func (s *Space) AdjustWith(indent string, t string) string {
+	return indent + strings.TrimRightFunc(t, unicode.IsSpace)
+}
+
+func ParseIndent(format string) (string, bool, error) {
+	if format == "" {
+		return "", false, nil
+	}
+	if !digitOnly.MatchString(format) {
+		return "", false, fmt.Errorf("space: invalid indent: %s", format)
+	}
+	n, err := strconv.Atoi(format)
+	if err != nil {
+		return "", false, err
+	}
+	return strings.Repeat(" ", n), true, nil
 }
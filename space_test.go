@@ -134,3 +134,49 @@ func TestSpaceAdjust(t *testing.T) {
 		}
 	}
 }
+
+var parseIndentTests = []struct {
+	src       string
+	indent    string
+	hasIndent bool
+}{
+	{"", "", false},
+	{"0", "", true},
+	{"2", "  ", true},
+	{"4", "    ", true},
+}
+
+func TestParseIndent(t *testing.T) {
+	for _, test := range parseIndentTests {
+		indent, hasIndent, err := ParseIndent(test.src)
+		if err != nil {
+			t.Errorf("ParseIndent(%q) returns %q; want nil",
+				test.src, err)
+			continue
+		}
+		if indent != test.indent || hasIndent != test.hasIndent {
+			t.Errorf("ParseIndent(%q) = %q, %v; want %q, %v",
+				test.src, indent, hasIndent, test.indent, test.hasIndent)
+		}
+	}
+}
+
+func TestParseIndentErr(t *testing.T) {
+	for _, src := range []string{"-1", "a", "2:2"} {
+		if _, _, err := ParseIndent(src); err == nil {
+			t.Errorf("ParseIndent(%q) returns nil; want err",
+				src)
+		}
+	}
+}
+
+func TestSpaceAdjustWith(t *testing.T) {
+	s := NewSpace()
+	s.UpdateLeadingWidth("\t a")
+	expect := "  a = b"
+	actual := s.AdjustWith("  ", "a = b  ")
+	if actual != expect {
+		t.Errorf("AdjustWith(%q, %q) = %q; want %q",
+			"  ", "a = b  ", actual, expect)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+type WidthModel int
+
+const (
+	WidthGrapheme WidthModel = iota
+	WidthRune
+)
+
+func ParseWidthModel(s string) (WidthModel, error) {
+	switch s {
+	case "", "grapheme":
+		return WidthGrapheme, nil
+	case "rune":
+		return WidthRune, nil
+	default:
+		return 0, fmt.Errorf("width: invalid model: %s", s)
+	}
+}
+
+func ParseEastAsianWidth(s string) (*runewidth.Condition, error) {
+	c := runewidth.NewCondition()
+	switch s {
+	case "", "auto":
+		c.EastAsianWidth = runewidth.IsEastAsian()
+	case "narrow":
+		c.EastAsianWidth = false
+	case "wide":
+		c.EastAsianWidth = true
+	default:
+		return nil, fmt.Errorf("width: invalid east asian width: %s", s)
+	}
+	return c, nil
+}
+
+type Measure struct {
+	model WidthModel
+	cond  *runewidth.Condition
+}
+
+var DefaultMeasure = NewMeasure(WidthGrapheme)
+
+func NewMeasure(model WidthModel) *Measure {
+	return &Measure{
+		model: model,
+		cond:  runewidth.DefaultCondition,
+	}
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+func (m *Measure) clusterWidth(rs []rune) int {
+	w := 0
+	for _, r := range rs {
+		if w = m.cond.RuneWidth(r); w > 0 {
+			break
+		}
+	}
+	if len(rs) < 2 {
+		return w
+	}
+	if isRegionalIndicator(rs[0]) && isRegionalIndicator(rs[1]) {
+		return 2
+	}
+	for _, r := range rs[1:] {
+		if r == 0xFE0F {
+			return 2
+		}
+	}
+	return w
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Measure) each(s string, f func(t string, w int) bool) {
+	for len(s) > 0 {
+		end := len(s)
+		if strings.IndexByte(s, 0x1b) >= 0 {
+			if loc := EscapeSequence.FindStringIndex(s); loc != nil {
+				if loc[0] == 0 {
+					if !f(s[:loc[1]], 0) {
+						return
+					}
+					s = s[loc[1]:]
+					continue
+				}
+				end = loc[0]
+			}
+		}
+		switch {
+		case isPrintableASCII(s[:end]):
+			for i := 0; i < end; i++ {
+				if !f(s[i:i+1], 1) {
+					return
+				}
+			}
+		case m.model == WidthRune:
+			for _, c := range s[:end] {
+				if !f(string(c), m.cond.RuneWidth(c)) {
+					return
+				}
+			}
+		default:
+			g := uniseg.NewGraphemes(s[:end])
+			for g.Next() {
+				if !f(g.Str(), m.clusterWidth(g.Runes())) {
+					return
+				}
+			}
+		}
+		s = s[end:]
+	}
+}
+
+func (m *Measure) StringWidth(s string) int {
+	if isPrintableASCII(s) {
+		return len(s)
+	}
+	if m.model == WidthRune {
+		return m.cond.StringWidth(StripEscape(s))
+	}
+	width := 0
+	m.each(s, func(t string, w int) bool {
+		width += w
+		return true
+	})
+	return width
+}
+
+func (m *Measure) CutIndex(s string, width int) int {
+	if isPrintableASCII(s) {
+		switch {
+		case width < 0:
+			return 0
+		case width < len(s):
+			return width
+		}
+		return len(s)
+	}
+	i, total := 0, 0
+	m.each(s, func(t string, w int) bool {
+		if total+w > width {
+			return false
+		}
+		i, total = i+len(t), total+w
+		return true
+	})
+	return i
+}
+
+func (m *Measure) FirstIndex(s string) int {
+	i := 0
+	m.each(s, func(t string, w int) bool {
+		i += len(t)
+		return w == 0 && i < len(s)
+	})
+	if i == 0 && len(s) > 0 {
+		_, i = utf8.DecodeRuneInString(s)
+	}
+	return i
+}
+
+func (m *Measure) Fill(fill string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	fw := m.StringWidth(fill)
+	if fill == " " || fw == 0 {
+		return strings.Repeat(" ", width)
+	}
+	n := width / fw
+	return strings.Repeat(fill, n) + strings.Repeat(" ", width-n*fw)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"testing"
+)
+
+var measureStringWidthTests = []struct {
+	model WidthModel
+	src   string
+	width int
+}{
+	{WidthGrapheme, "", 0},
+	{WidthGrapheme, "abc", 3},
+	{WidthGrapheme, "日本語", 6},
+	{WidthGrapheme, "\x1b[31mabc\x1b[0m", 3},
+	{WidthGrapheme, "\x1b]8;;http://example.com\x07abc\x1b]8;;\x07", 3},
+	{WidthGrapheme, "\x1b[01;34m日本語\x1b[0m", 6},
+	{WidthGrapheme, "key = value # comment", 21},
+	{WidthGrapheme, "ab日本", 6},
+
+	// grapheme clusters
+	{WidthGrapheme, "é", 1},
+	{WidthGrapheme, "\U0001F1EF\U0001F1F5", 2},
+	{WidthGrapheme, "\U0001F1EF\U0001F1F5\U0001F1FA\U0001F1F8", 4},
+	{WidthGrapheme, "\U0001F468‍\U0001F469‍\U0001F467", 2},
+	{WidthGrapheme, "❤️", 2},
+	{WidthGrapheme, "a\U0001F44D\U0001F3FDb", 4},
+
+	// runes
+	{WidthRune, "abc", 3},
+	{WidthRune, "日本語", 6},
+	{WidthRune, "\x1b[31mabc\x1b[0m", 3},
+	{WidthRune, "é", 1},
+}
+
+func TestMeasureStringWidth(t *testing.T) {
+	for _, test := range measureStringWidthTests {
+		m := NewMeasure(test.model)
+
+		expect := test.width
+		actual := m.StringWidth(test.src)
+		if actual != expect {
+			t.Errorf("NewMeasure(%v).StringWidth(%q) = %v; want %v",
+				test.model, test.src, actual, expect)
+		}
+	}
+}
+
+var measureCutIndexTests = []struct {
+	src   string
+	width int
+	index int
+}{
+	{"abc", 0, 0},
+	{"abc", 2, 2},
+	{"abc", 5, 3},
+	{"abc", -1, 0},
+	{"ab日本", 3, 2},
+	{"日本語", 3, 3},
+	{"日本語", 4, 6},
+	{"\x1b[31mabc\x1b[0m", 1, 6},
+	{"\x1b[31mabc\x1b[0m", 3, 12},
+	{"éé", 1, 3},
+	{"\U0001F1EF\U0001F1F5a", 1, 0},
+	{"\U0001F1EF\U0001F1F5a", 2, 8},
+}
+
+func TestMeasureCutIndex(t *testing.T) {
+	m := NewMeasure(WidthGrapheme)
+	for _, test := range measureCutIndexTests {
+		expect := test.index
+		actual := m.CutIndex(test.src, test.width)
+		if actual != expect {
+			t.Errorf("CutIndex(%q, %v) = %v; want %v",
+				test.src, test.width, actual, expect)
+		}
+	}
+}
+
+var parseWidthModelErrTests = []string{
+	"Rune",
+	"graphemes",
+	"byte",
+}
+
+func TestParseWidthModelErr(t *testing.T) {
+	for _, s := range parseWidthModelErrTests {
+		_, err := ParseWidthModel(s)
+		if err == nil {
+			t.Errorf("ParseWidthModel(%q) returns nil; want err", s)
+		}
+	}
+}
+
+var measureEastAsianWidthTests = []struct {
+	eastAsian string
+	src       string
+	width     int
+}{
+	{"narrow", "○→α", 3},
+	{"wide", "○→α", 6},
+	{"narrow", "日本語", 6},
+	{"wide", "日本語", 6},
+	{"narrow", "abc", 3},
+	{"wide", "abc", 3},
+}
+
+func TestMeasureEastAsianWidth(t *testing.T) {
+	for _, test := range measureEastAsianWidthTests {
+		cond, err := ParseEastAsianWidth(test.eastAsian)
+		if err != nil {
+			t.Errorf("ParseEastAsianWidth(%q) returns %q; want nil",
+				test.eastAsian, err)
+			continue
+		}
+		for _, model := range []WidthModel{WidthGrapheme, WidthRune} {
+			m := NewMeasure(model)
+			m.cond = cond
+
+			expect := test.width
+			actual := m.StringWidth(test.src)
+			if actual != expect {
+				t.Errorf("%q: NewMeasure(%v).StringWidth(%q) = %v; want %v",
+					test.eastAsian, model, test.src, actual, expect)
+			}
+		}
+	}
+}
+
+var parseEastAsianWidthErrTests = []string{
+	"Wide",
+	"ambiguous",
+	"1",
+}
+
+func TestParseEastAsianWidthErr(t *testing.T) {
+	for _, s := range parseEastAsianWidthErrTests {
+		_, err := ParseEastAsianWidth(s)
+		if err == nil {
+			t.Errorf("ParseEastAsianWidth(%q) returns nil; want err", s)
+		}
+	}
+}
+
+var measureFillTests = []struct {
+	fill  string
+	width int
+	dst   string
+}{
+	{" ", 3, "   "},
+	{".", 3, "..."},
+	{"-=", 5, "-=-= "},
+	{"・", 5, "・・ "},
+	{".", 0, ""},
+	{".", -1, ""},
+	{"\x1b[31m", 2, "  "},
+}
+
+func TestMeasureFill(t *testing.T) {
+	m := NewMeasure(WidthGrapheme)
+	for _, test := range measureFillTests {
+		expect := test.dst
+		actual := m.Fill(test.fill, test.width)
+		if actual != expect {
+			t.Errorf("Fill(%q, %v) = %q; want %q",
+				test.fill, test.width, actual, expect)
+		}
+	}
+}